@@ -16,6 +16,8 @@ func initSchedule(cr *cron.Cron, servs *service.Service) {
 	servs.AuthService.DeleteOldSessions()
 	servs.DatabasesService.TestAllDatabases()
 	servs.DestinationsService.TestAllDestinations()
+	servs.WalSegmentsService.DeleteExpiredSegments()
+	servs.ServersService.SyncAllServers()
 
 	/*
 		Schedules
@@ -58,5 +60,43 @@ func initSchedule(cr *cron.Cron, servs *service.Service) {
 		)
 	}
 
+	err = cr.UpsertJob(uuid.New(), "UTC", "* * * * *", func() {
+		servs.WalSegmentsService.ArchiveAll()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling WAL archiving", logger.KV{"error": err},
+		)
+	}
+
+	err = cr.UpsertJob(uuid.New(), "UTC", "*/10 * * * *", func() {
+		servs.WalSegmentsService.DeleteExpiredSegments()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling deletion of expired WAL segments",
+			logger.KV{"error": err},
+		)
+	}
+
+	err = cr.UpsertJob(uuid.New(), "UTC", "0 3 * * *", func() {
+		servs.ExecutionsService.ScanIntegrity()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling integrity scan of executions",
+			logger.KV{"error": err},
+		)
+	}
+
+	err = cr.UpsertJob(uuid.New(), "UTC", "*/10 * * * *", func() {
+		servs.ServersService.SyncAllServers()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling servers sync", logger.KV{"error": err},
+		)
+	}
+
 	servs.BackupsService.ScheduleAll()
 }
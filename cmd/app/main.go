@@ -37,7 +37,17 @@ func main() {
 	}()
 	dbgen := dbgen.New(db)
 
-	ints := integration.New()
+	ints, err := integration.New(env)
+	if err != nil {
+		logger.FatalError("error initializing integrations", logger.KV{"error": err})
+	}
+	for _, v := range ints.PGClient.Versions() {
+		logger.Info("PostgreSQL client binaries registered", logger.KV{
+			"version": v.Value.Version,
+			"pgDump":  v.Value.PGDump,
+		})
+	}
+
 	servs := service.New(env, dbgen, cr, ints)
 	initSchedule(cr, servs)
 
@@ -2,6 +2,7 @@ package config
 
 import (
 	"sync"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
@@ -12,6 +13,15 @@ type Env struct {
 	PBW_POSTGRES_CONN_STRING string `env:"PBW_POSTGRES_CONN_STRING,required"`
 	PBW_LISTEN_HOST          string `env:"PBW_LISTEN_HOST" envDefault:"0.0.0.0"`
 	PBW_LISTEN_PORT          string `env:"PBW_LISTEN_PORT" envDefault:"8085"`
+
+	// PBW_PG_BIN_ROOTS are scanned for <root>/<major>/bin client binaries.
+	PBW_PG_BIN_ROOTS []string `env:"PBW_PG_BIN_ROOTS" envDefault:"/usr/lib/postgresql" envSeparator:","`
+	// PBW_PG_BIN_DIRS declares client binaries explicitly, e.g. 18=/opt/pg18/bin.
+	PBW_PG_BIN_DIRS map[string]string `env:"PBW_PG_BIN_DIRS" envSeparator:"," envKeyValSeparator:"="`
+
+	// PBW_BACKUP_TIMEOUT is the maximum duration of the backups without their
+	// own timeout, 0 disables it.
+	PBW_BACKUP_TIMEOUT time.Duration `env:"PBW_BACKUP_TIMEOUT" envDefault:"24h"`
 }
 
 var (
@@ -16,5 +16,9 @@ func validateEnv(env Env) error {
 		return fmt.Errorf("invalid listen port %s, valid values are 1-65535", env.PBW_LISTEN_PORT)
 	}
 
+	if env.PBW_BACKUP_TIMEOUT < 0 {
+		return fmt.Errorf("invalid backup timeout %s, it can't be negative", env.PBW_BACKUP_TIMEOUT)
+	}
+
 	return nil
 }
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS opt_format TEXT NOT NULL DEFAULT 'plain'
+CHECK (opt_format IN ('plain', 'custom', 'directory'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_format;
+-- +goose StatementEnd
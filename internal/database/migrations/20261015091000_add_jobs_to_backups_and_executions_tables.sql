@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS opt_jobs SMALLINT NOT NULL DEFAULT 1
+CHECK (opt_jobs BETWEEN 1 AND 32);
+
+ALTER TABLE backups ADD CONSTRAINT backups_opt_jobs_format_check CHECK (
+  opt_jobs = 1 OR opt_format = 'directory'
+);
+
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS jobs SMALLINT NULL DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE executions DROP COLUMN IF EXISTS jobs;
+ALTER TABLE backups DROP CONSTRAINT IF EXISTS backups_opt_jobs_format_check;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS opt_schemas TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS opt_exclude_schemas TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS opt_tables TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS opt_exclude_tables TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS opt_exclude_table_data TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+DROP COLUMN IF EXISTS opt_schemas,
+DROP COLUMN IF EXISTS opt_exclude_schemas,
+DROP COLUMN IF EXISTS opt_tables,
+DROP COLUMN IF EXISTS opt_exclude_tables,
+DROP COLUMN IF EXISTS opt_exclude_table_data;
+-- +goose StatementEnd
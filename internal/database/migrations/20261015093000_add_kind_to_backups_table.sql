@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'database'
+CHECK (kind IN ('database', 'globals', 'roles'));
+
+ALTER TABLE backups ADD CONSTRAINT backups_kind_format_check CHECK (
+  kind = 'database' OR opt_format = 'plain'
+);
+
+ALTER TABLE restorations
+ADD COLUMN IF NOT EXISTS globals_execution_id UUID NULL DEFAULT NULL
+REFERENCES executions(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE restorations DROP COLUMN IF EXISTS globals_execution_id;
+ALTER TABLE backups DROP CONSTRAINT IF EXISTS backups_kind_format_check;
+ALTER TABLE backups DROP COLUMN IF EXISTS kind;
+-- +goose StatementEnd
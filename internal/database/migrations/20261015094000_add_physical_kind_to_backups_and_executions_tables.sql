@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups DROP CONSTRAINT IF EXISTS backups_kind_check;
+ALTER TABLE backups ADD CONSTRAINT backups_kind_check CHECK (
+  kind IN ('database', 'globals', 'roles', 'physical')
+);
+
+ALTER TABLE executions
+ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'database'
+CHECK (kind IN ('database', 'globals', 'roles', 'physical'));
+
+UPDATE executions SET kind = backups.kind
+FROM backups WHERE backups.id = executions.backup_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Physical backups and their executions must be deleted by hand, they are
+-- not removed silently
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM backups WHERE kind = 'physical') THEN
+    RAISE EXCEPTION 'physical backups exist, delete them before rolling back';
+  END IF;
+END
+$$;
+
+ALTER TABLE executions DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE backups DROP CONSTRAINT IF EXISTS backups_kind_check;
+ALTER TABLE backups ADD CONSTRAINT backups_kind_check CHECK (
+  kind IN ('database', 'globals', 'roles')
+);
+-- +goose StatementEnd
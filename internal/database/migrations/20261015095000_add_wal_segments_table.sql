@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS opt_wal_archiving BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE backups ADD CONSTRAINT backups_opt_wal_archiving_kind_check CHECK (
+  opt_wal_archiving = false OR kind = 'physical'
+);
+
+CREATE TABLE IF NOT EXISTS wal_segments (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  backup_id UUID NOT NULL REFERENCES backups(id) ON DELETE CASCADE,
+
+  name TEXT NOT NULL,
+  path TEXT NOT NULL,
+  file_size BIGINT NOT NULL,
+
+  archived_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  UNIQUE (backup_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS
+idx_wal_segments_backup_id_archived_at ON wal_segments(backup_id, archived_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS wal_segments;
+ALTER TABLE backups DROP CONSTRAINT IF EXISTS backups_opt_wal_archiving_kind_check;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_wal_archiving;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE databases
+ADD COLUMN IF NOT EXISTS pg_version_warning TEXT NULL DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE databases DROP COLUMN IF EXISTS pg_version_warning;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE databases
+  DROP CONSTRAINT IF EXISTS databases_pg_version_check,
+  ADD CONSTRAINT databases_pg_version_check
+  CHECK (pg_version ~ '^[0-9]+$');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Databases of newer versions must be deleted by hand before rolling back,
+-- the old constraint only accepts PostgreSQL 13 to 17
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM databases
+    WHERE pg_version NOT IN ('13', '14', '15', '16', '17')
+  ) THEN
+    RAISE EXCEPTION 'databases with PostgreSQL versions other than 13 to 17 exist, delete them before rolling back';
+  END IF;
+END
+$$;
+
+ALTER TABLE databases
+  DROP CONSTRAINT IF EXISTS databases_pg_version_check,
+  ADD CONSTRAINT databases_pg_version_check
+  CHECK (pg_version IN ('13', '14', '15', '16', '17'));
+-- +goose StatementEnd
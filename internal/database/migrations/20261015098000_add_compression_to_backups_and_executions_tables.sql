@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS opt_compression TEXT NOT NULL DEFAULT 'gzip'
+CHECK (opt_compression IN ('none', 'gzip', 'zstd', 'lz4')),
+ADD COLUMN IF NOT EXISTS opt_compression_level SMALLINT NOT NULL DEFAULT 0
+CHECK (opt_compression_level BETWEEN 0 AND 19);
+
+-- Executions record how their artifact was created so it can be restored
+-- even if the backup changes later, old executions are ZIP files
+ALTER TABLE executions
+ADD COLUMN IF NOT EXISTS format TEXT NOT NULL DEFAULT 'plain'
+CHECK (format IN ('plain', 'custom', 'directory')),
+ADD COLUMN IF NOT EXISTS compression TEXT NOT NULL DEFAULT 'zip'
+CHECK (compression IN ('zip', 'none', 'gzip', 'zstd', 'lz4'));
+
+UPDATE executions SET format = backups.opt_format
+FROM backups WHERE backups.id = executions.backup_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE executions
+DROP COLUMN IF EXISTS format,
+DROP COLUMN IF EXISTS compression;
+
+ALTER TABLE backups
+DROP COLUMN IF EXISTS opt_compression,
+DROP COLUMN IF EXISTS opt_compression_level;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS verify_is_active BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS verify_cron_expression TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS verify_database_id UUID
+REFERENCES databases(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS verify_checks TEXT[] NOT NULL DEFAULT '{}';
+
+-- Outcome of the last restore drill of the execution
+ALTER TABLE executions
+ADD COLUMN IF NOT EXISTS verification_status TEXT
+CHECK (verification_status IN ('running', 'success', 'failed')),
+ADD COLUMN IF NOT EXISTS verification_message TEXT,
+ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ;
+
+ALTER TABLE webhooks
+  DROP CONSTRAINT IF EXISTS webhooks_event_type_check,
+  ADD CONSTRAINT webhooks_event_type_check
+  CHECK (event_type IN (
+    'database_healthy', 'database_unhealthy',
+    'destination_healthy', 'destination_unhealthy',
+    'execution_success', 'execution_failed',
+    'verification_success', 'verification_failed'
+  ));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM webhooks
+WHERE event_type IN ('verification_success', 'verification_failed');
+
+ALTER TABLE webhooks
+  DROP CONSTRAINT IF EXISTS webhooks_event_type_check,
+  ADD CONSTRAINT webhooks_event_type_check
+  CHECK (event_type IN (
+    'database_healthy', 'database_unhealthy',
+    'destination_healthy', 'destination_unhealthy',
+    'execution_success', 'execution_failed'
+  ));
+
+ALTER TABLE executions
+DROP COLUMN IF EXISTS verification_status,
+DROP COLUMN IF EXISTS verification_message,
+DROP COLUMN IF EXISTS verified_at;
+
+ALTER TABLE backups
+DROP COLUMN IF EXISTS verify_is_active,
+DROP COLUMN IF EXISTS verify_cron_expression,
+DROP COLUMN IF EXISTS verify_database_id,
+DROP COLUMN IF EXISTS verify_checks;
+-- +goose StatementEnd
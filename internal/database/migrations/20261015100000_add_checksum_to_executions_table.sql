@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The checksum is the hex encoded SHA-256 of the artifact, old executions
+-- don't have it. The integrity columns are filled by the integrity scan.
+ALTER TABLE executions
+ADD COLUMN IF NOT EXISTS checksum TEXT,
+ADD COLUMN IF NOT EXISTS integrity_status TEXT
+CHECK (integrity_status IN ('ok', 'corrupted', 'missing')),
+ADD COLUMN IF NOT EXISTS integrity_checked_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE executions
+DROP COLUMN IF EXISTS checksum,
+DROP COLUMN IF EXISTS integrity_status,
+DROP COLUMN IF EXISTS integrity_checked_at;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The passphrase is encrypted with PBW_ENCRYPTION_KEY. Executions keep the
+-- passphrase their artifact was encrypted with, so they can be decrypted
+-- even if the passphrase of the backup changes later.
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS encryption_passphrase BYTEA;
+
+ALTER TABLE executions
+ADD COLUMN IF NOT EXISTS encryption_passphrase BYTEA;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE executions
+DROP COLUMN IF EXISTS encryption_passphrase;
+
+ALTER TABLE backups
+DROP COLUMN IF EXISTS encryption_passphrase;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The private key is encrypted with PBW_ENCRYPTION_KEY like the connection
+-- string. The tunnel is disabled when ssh_host is NULL.
+ALTER TABLE databases
+ADD COLUMN IF NOT EXISTS ssh_host TEXT,
+ADD COLUMN IF NOT EXISTS ssh_user TEXT,
+ADD COLUMN IF NOT EXISTS ssh_private_key BYTEA,
+ADD COLUMN IF NOT EXISTS ssh_host_key TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE databases
+DROP COLUMN IF EXISTS ssh_host,
+DROP COLUMN IF EXISTS ssh_user,
+DROP COLUMN IF EXISTS ssh_private_key,
+DROP COLUMN IF EXISTS ssh_host_key;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The PEM files are encrypted with PBW_ENCRYPTION_KEY like the connection
+-- string, they are written to temporary files while they are used.
+ALTER TABLE databases
+ADD COLUMN IF NOT EXISTS tls_root_cert BYTEA,
+ADD COLUMN IF NOT EXISTS tls_client_cert BYTEA,
+ADD COLUMN IF NOT EXISTS tls_client_key BYTEA;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE databases
+DROP COLUMN IF EXISTS tls_root_cert,
+DROP COLUMN IF EXISTS tls_client_cert,
+DROP COLUMN IF EXISTS tls_client_key;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The less common pg_dump options are stored as a JSON document validated by
+-- the application, so new options don't need a column each.
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS opt_dump_options JSONB NOT NULL DEFAULT '{}'::JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+DROP COLUMN IF EXISTS opt_dump_options;
+-- +goose StatementEnd
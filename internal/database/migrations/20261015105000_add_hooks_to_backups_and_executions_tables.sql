@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS pre_hook_sql TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS pre_hook_command TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS post_hook_sql TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS post_hook_command TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS hook_timeout_seconds INTEGER NOT NULL DEFAULT 300
+CHECK (hook_timeout_seconds BETWEEN 1 AND 86400),
+ADD COLUMN IF NOT EXISTS hook_failure_policy TEXT NOT NULL DEFAULT 'abort'
+CHECK (hook_failure_policy IN ('abort', 'continue'));
+
+-- Combined output of the hooks that ran in the execution
+ALTER TABLE executions
+ADD COLUMN IF NOT EXISTS hooks_output TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE executions
+DROP COLUMN IF EXISTS hooks_output;
+
+ALTER TABLE backups
+DROP COLUMN IF EXISTS pre_hook_sql,
+DROP COLUMN IF EXISTS pre_hook_command,
+DROP COLUMN IF EXISTS post_hook_sql,
+DROP COLUMN IF EXISTS post_hook_command,
+DROP COLUMN IF EXISTS hook_timeout_seconds,
+DROP COLUMN IF EXISTS hook_failure_policy;
+-- +goose StatementEnd
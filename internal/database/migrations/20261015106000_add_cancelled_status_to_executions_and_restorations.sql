@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE executions
+  DROP CONSTRAINT IF EXISTS executions_status_check,
+  ADD CONSTRAINT executions_status_check
+  CHECK (status IN ('running', 'success', 'failed', 'deleted', 'cancelled'));
+
+ALTER TABLE restorations
+  DROP CONSTRAINT IF EXISTS restorations_status_check,
+  ADD CONSTRAINT restorations_status_check
+  CHECK (status IN ('running', 'success', 'failed', 'cancelled'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE executions SET status = 'failed' WHERE status = 'cancelled';
+UPDATE restorations SET status = 'failed' WHERE status = 'cancelled';
+
+ALTER TABLE executions
+  DROP CONSTRAINT IF EXISTS executions_status_check,
+  ADD CONSTRAINT executions_status_check
+  CHECK (status IN ('running', 'success', 'failed', 'deleted'));
+
+ALTER TABLE restorations
+  DROP CONSTRAINT IF EXISTS restorations_status_check,
+  ADD CONSTRAINT restorations_status_check
+  CHECK (status IN ('running', 'success', 'failed'));
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Maximum duration of the executions, 0 uses the PBW_BACKUP_TIMEOUT default
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS timeout_minutes INTEGER NOT NULL DEFAULT 0
+CHECK (timeout_minutes >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+DROP COLUMN IF EXISTS timeout_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS retry_max_attempts INTEGER NOT NULL DEFAULT 1
+CHECK (retry_max_attempts BETWEEN 1 AND 10),
+ADD COLUMN IF NOT EXISTS retry_initial_delay_seconds INTEGER NOT NULL DEFAULT 60
+CHECK (retry_initial_delay_seconds BETWEEN 1 AND 86400),
+ADD COLUMN IF NOT EXISTS retry_backoff_factor DOUBLE PRECISION NOT NULL DEFAULT 2
+CHECK (retry_backoff_factor BETWEEN 1 AND 10);
+
+-- The attempts of the same scheduled run share the run ID
+ALTER TABLE executions
+ADD COLUMN IF NOT EXISTS run_id UUID NOT NULL DEFAULT uuid_generate_v4(),
+ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS
+idx_executions_run_id ON executions(run_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_executions_run_id;
+
+ALTER TABLE executions
+DROP COLUMN IF EXISTS run_id,
+DROP COLUMN IF EXISTS attempt;
+
+ALTER TABLE backups
+DROP COLUMN IF EXISTS retry_max_attempts,
+DROP COLUMN IF EXISTS retry_initial_delay_seconds,
+DROP COLUMN IF EXISTS retry_backoff_factor;
+-- +goose StatementEnd
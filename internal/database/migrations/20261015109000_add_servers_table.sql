@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A server discovers the databases of a cluster using the connection of one
+-- of its databases and backs up every one of them using a template backup.
+CREATE TABLE IF NOT EXISTS servers (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+  template_backup_id UUID NOT NULL REFERENCES backups(id) ON DELETE CASCADE,
+
+  name TEXT NOT NULL UNIQUE,
+  include_patterns TEXT[] NOT NULL DEFAULT '{}',
+  exclude_patterns TEXT[] NOT NULL DEFAULT '{}',
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+  last_sync_at TIMESTAMPTZ,
+  last_sync_error TEXT,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE TRIGGER servers_change_updated_at
+BEFORE UPDATE ON servers FOR EACH ROW EXECUTE FUNCTION change_updated_at();
+
+-- The databases and backups created by a server keep working as regular ones
+-- if the server is deleted
+ALTER TABLE databases
+ADD COLUMN IF NOT EXISTS server_id UUID REFERENCES servers(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS server_database_name TEXT,
+ADD COLUMN IF NOT EXISTS missing_since TIMESTAMPTZ,
+ADD CONSTRAINT databases_server_id_server_database_name_key
+UNIQUE (server_id, server_database_name);
+
+ALTER TABLE backups
+ADD COLUMN IF NOT EXISTS server_id UUID REFERENCES servers(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS
+idx_backups_server_id ON backups(server_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_backups_server_id;
+
+ALTER TABLE backups
+DROP COLUMN IF EXISTS server_id;
+
+ALTER TABLE databases
+DROP CONSTRAINT IF EXISTS databases_server_id_server_database_name_key,
+DROP COLUMN IF EXISTS server_id,
+DROP COLUMN IF EXISTS server_database_name,
+DROP COLUMN IF EXISTS missing_since;
+
+DROP TABLE IF EXISTS servers;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- WAL segments are encrypted with the passphrase of their backup, like the
+-- executions they keep the passphrase they were encrypted with.
+ALTER TABLE wal_segments
+ADD COLUMN IF NOT EXISTS encryption_passphrase BYTEA;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE wal_segments
+DROP COLUMN IF EXISTS encryption_passphrase;
+-- +goose StatementEnd
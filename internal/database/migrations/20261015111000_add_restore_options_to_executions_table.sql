@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Executions record the pg_dump options that change how their artifact must
+-- be restored, the options of the backup can change after the dump
+ALTER TABLE executions
+ADD COLUMN IF NOT EXISTS opt_clean BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS opt_if_exists BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS opt_create BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS opt_no_comments BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The current options of the backup are the best guess for old executions
+UPDATE executions SET
+  opt_clean = backups.opt_clean,
+  opt_if_exists = backups.opt_if_exists,
+  opt_create = backups.opt_create,
+  opt_no_comments = backups.opt_no_comments
+FROM backups WHERE backups.id = executions.backup_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE executions
+DROP COLUMN IF EXISTS opt_clean,
+DROP COLUMN IF EXISTS opt_if_exists,
+DROP COLUMN IF EXISTS opt_create,
+DROP COLUMN IF EXISTS opt_no_comments;
+-- +goose StatementEnd
@@ -1,6 +1,9 @@
 package integration
 
 import (
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/config"
 	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/integration/storage"
 )
@@ -10,12 +13,23 @@ type Integration struct {
 	StorageClient *storage.Client
 }
 
-func New() *Integration {
-	pgClient := postgres.New()
+func New(env config.Env) (*Integration, error) {
+	pgVersions, err := postgres.DiscoverVersions(postgres.DiscoverVersionsParams{
+		BinRoots: env.PBW_PG_BIN_ROOTS,
+		BinDirs:  env.PBW_PG_BIN_DIRS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error discovering PostgreSQL versions: %w", err)
+	}
+
+	pgClient, err := postgres.New(pgVersions)
+	if err != nil {
+		return nil, err
+	}
 	storageClient := storage.New()
 
 	return &Integration{
 		PGClient:      pgClient,
 		StorageClient: storageClient,
-	}
+	}, nil
 }
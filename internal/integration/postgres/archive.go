@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeTar writes all the files inside dir to w as a tar archive, using paths
+// relative to dir.
+func writeTar(w io.Writer, dir string) error {
+	tarWriter := tar.NewWriter(w)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return fmt.Errorf("error creating tar header: %w", err)
+		}
+		header.Name = filepath.ToSlash(relPath)
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("error writing tar header: %w", err)
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("error opening dump file: %w", err)
+		}
+		defer file.Close()
+
+		if _, err := io.Copy(tarWriter, file); err != nil {
+			return fmt.Errorf("error writing to tar file: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return tarWriter.Close()
+}
+
+// extractTar extracts the regular files of the tar archive read from r into
+// dir, rejecting entries that would be written outside of it.
+func extractTar(r io.Reader, dir string) error {
+	tarReader := tar.NewReader(r)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar file: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := extractFile(tarReader, dir, header.Name); err != nil {
+			return err
+		}
+	}
+}
+
+// extractZipDir extracts the files of the ZIP archive under the prefix
+// directory into dir, rejecting entries that would be written outside of it.
+func extractZipDir(zipReader *zip.Reader, prefix string, dir string) error {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+
+	for _, file := range zipReader.File {
+		if !strings.HasPrefix(file.Name, prefix) || file.FileInfo().IsDir() {
+			continue
+		}
+
+		fileReader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("error reading ZIP file: %w", err)
+		}
+
+		err = extractFile(fileReader, dir, strings.TrimPrefix(file.Name, prefix))
+		fileReader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractFile writes the content of r to the file with the given slash
+// separated name relative to dir.
+func extractFile(r io.Reader, dir string, name string) error {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return fmt.Errorf("invalid path in archive: %s", name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+
+	_, err = io.Copy(file, r)
+	closeErr := file.Close()
+	if err != nil {
+		return fmt.Errorf("error extracting %s: %w", name, err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("error extracting %s: %w", name, closeErr)
+	}
+
+	return nil
+}
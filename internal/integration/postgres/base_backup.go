@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// baseBackupZipEntry is the name of the tar file that holds the base backup
+	// inside the ZIP file generated by BaseBackupZip.
+	baseBackupZipEntry = "base.tar"
+
+	// recoveryZipEntry is the name of the file with the recovery instructions
+	// inside the ZIP file generated by BaseBackupZip.
+	recoveryZipEntry = "RECOVERY.txt"
+)
+
+// BaseBackupZip runs the pg_basebackup command to take a physical copy of the
+// whole cluster and returns it ZIP-compressed as an io.Reader.
+//
+// The ZIP file contains a base.tar file with the data directory, including the
+// WAL needed to make it consistent, and a RECOVERY.txt file with the steps to
+// restore it manually.
+//
+// The connection string must belong to a role with the REPLICATION attribute
+// and the server must allow replication connections from PG Back Web. The
+// cluster can't use additional tablespaces. pg_basebackup is killed if the
+// context is done before it finishes.
+func (Client) BaseBackupZip(
+	ctx context.Context, version PGVersion, connString string,
+) io.Reader {
+	connString, env := hidePassword(connString)
+	reader, writer := io.Pipe()
+
+	go func() {
+		defer writer.Close()
+
+		zipWriter := zip.NewWriter(writer)
+		defer zipWriter.Close()
+
+		err := writeZipEntry(
+			zipWriter, recoveryZipEntry, zip.Deflate,
+			strings.NewReader(BaseBackupRecoveryInstructions(version.Value.Version)),
+		)
+		if err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+
+		backupReader, backupWriter := io.Pipe()
+		errorBuffer := &bytes.Buffer{}
+		cmd := commandContext(
+			ctx,
+			version.Value.PGBaseBackup,
+			"--dbname="+connString,
+			"--pgdata=-",
+			"--format=tar",
+			"--wal-method=fetch",
+			"--label=pgbackweb",
+		)
+		cmd.Env = env
+		cmd.Stdout = backupWriter
+		cmd.Stderr = errorBuffer
+
+		go func() {
+			defer backupWriter.Close()
+			if err := cmd.Run(); err != nil {
+				if ctx.Err() != nil {
+					backupWriter.CloseWithError(ctx.Err())
+					return
+				}
+				backupWriter.CloseWithError(fmt.Errorf(
+					"error running pg_basebackup v%s: %s",
+					version.Value.Version, errorBuffer.String(),
+				))
+			}
+		}()
+
+		err = writeZipEntry(
+			zipWriter, baseBackupZipEntry, zip.Deflate, backupReader,
+		)
+		if err != nil {
+			writer.CloseWithError(err)
+		}
+	}()
+
+	return reader
+}
+
+// BaseBackupRecoveryInstructions returns the steps to restore a physical base
+// backup generated by BaseBackupZip.
+func BaseBackupRecoveryInstructions(pgVersion string) string {
+	return fmt.Sprintf(`Physical base backup taken with pg_basebackup v%[1]s
+
+This backup is a copy of the whole data directory of the cluster, it can't
+be restored by PG Back Web and must be restored on a server running
+PostgreSQL %[1]s. The WAL needed to make it consistent is already included,
+so no restore_command is required.
+
+1. Stop the PostgreSQL server:
+     pg_ctl stop -D "$PGDATA"
+
+2. Move the current data directory away (or delete it):
+     mv "$PGDATA" "$PGDATA.old"
+
+3. Create an empty data directory owned by the postgres user:
+     mkdir -m 700 "$PGDATA"
+     chown postgres:postgres "$PGDATA"
+
+4. Extract the base backup into it:
+     unzip <backup-file>.zip %[2]s
+     tar -xf %[2]s -C "$PGDATA"
+
+5. If your distribution keeps postgresql.conf and pg_hba.conf outside the
+   data directory, make sure they match the ones of the backed up server.
+
+6. Start the PostgreSQL server:
+     pg_ctl start -D "$PGDATA"
+`, pgVersion, baseBackupZipEntry)
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RunCheck runs a sanity check query using psql and returns an error if the
+// query fails or doesn't return true in the first column of its first row.
+//
+// For example:
+//
+//	SELECT count(*) > 0 FROM public.users
+//	SELECT to_regclass('public.orders') IS NOT NULL
+func (Client) RunCheck(
+	ctx context.Context, version PGVersion, connString string, query string,
+) error {
+	connString, env := hidePassword(connString)
+
+	errorBuffer := &bytes.Buffer{}
+	cmd := commandContext(
+		ctx, version.Value.PSQL, connString, "-X", "--no-align", "--tuples-only",
+		"-v", "ON_ERROR_STOP=1", "-c", query,
+	)
+	cmd.Env = env
+	cmd.Stderr = errorBuffer
+
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("check %q failed: %s", query, errorBuffer.String())
+	}
+
+	firstRow, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	firstColumn, _, _ := strings.Cut(firstRow, "|")
+	if firstColumn != "t" {
+		return fmt.Errorf("check %q returned %q instead of true", query, firstRow)
+	}
+
+	return nil
+}
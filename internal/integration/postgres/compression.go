@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/orsinium-labs/enum"
+	"github.com/pierrec/lz4/v4"
+)
+
+type compression struct {
+	Key  string
+	Name string
+	// Extension is appended to the artifact file name after the extension of
+	// the dump format, for example dump.sql.gz.
+	Extension string
+	// MaxLevel is the highest compression level accepted by the codec, the
+	// lowest is always 1. It is 0 for codecs without levels.
+	MaxLevel int
+}
+
+type Compression enum.Member[compression]
+
+var (
+	CompressionNone = Compression{compression{
+		Key:       "none",
+		Name:      "None",
+		Extension: "",
+		MaxLevel:  0,
+	}}
+	CompressionGzip = Compression{compression{
+		Key:       "gzip",
+		Name:      "gzip",
+		Extension: ".gz",
+		MaxLevel:  9,
+	}}
+	CompressionZstd = Compression{compression{
+		Key:       "zstd",
+		Name:      "Zstandard",
+		Extension: ".zst",
+		MaxLevel:  19,
+	}}
+	CompressionLZ4 = Compression{compression{
+		Key:       "lz4",
+		Name:      "LZ4",
+		Extension: ".lz4",
+		MaxLevel:  12,
+	}}
+
+	// CompressionZip is the ZIP container used by executions created before
+	// the compression codec could be selected. It can't be selected for new
+	// backups and is only kept to restore those executions using RestoreZip.
+	CompressionZip = Compression{compression{
+		Key:       "zip",
+		Name:      "ZIP (legacy)",
+		Extension: ".zip",
+		MaxLevel:  0,
+	}}
+
+	// Compressions are the codecs that can be selected for a backup.
+	Compressions = []Compression{
+		CompressionZstd, CompressionGzip, CompressionLZ4, CompressionNone,
+	}
+)
+
+// ParseCompression returns the Compression enum member for the given key.
+func (Client) ParseCompression(key string) (Compression, error) {
+	switch key {
+	case CompressionNone.Value.Key:
+		return CompressionNone, nil
+	case CompressionGzip.Value.Key:
+		return CompressionGzip, nil
+	case CompressionZstd.Value.Key:
+		return CompressionZstd, nil
+	case CompressionLZ4.Value.Key:
+		return CompressionLZ4, nil
+	case CompressionZip.Value.Key:
+		return CompressionZip, nil
+	default:
+		return Compression{}, fmt.Errorf("compression not allowed: %s", key)
+	}
+}
+
+// ValidateLevel returns an error if the level is not accepted by the codec.
+// Level 0 is always accepted and means the default level of the codec.
+func (c Compression) ValidateLevel(level int) error {
+	if level == 0 {
+		return nil
+	}
+
+	if c.Value.MaxLevel == 0 {
+		return fmt.Errorf("%s compression does not support levels", c.Value.Name)
+	}
+
+	if level < 1 || level > c.Value.MaxLevel {
+		return fmt.Errorf(
+			"%s compression level must be between 1 and %d",
+			c.Value.Name, c.Value.MaxLevel,
+		)
+	}
+
+	return nil
+}
+
+// ArtifactExtension returns the file extension of an artifact with the given
+// dump format and compression, for example .sql.zst or .tar.gz.
+func ArtifactExtension(format DumpFormat, comp Compression) string {
+	if comp == CompressionZip {
+		return comp.Value.Extension
+	}
+	return format.Value.Extension + comp.Value.Extension
+}
+
+// Compress returns the content of the reader compressed with the given codec
+// and level, level 0 uses the default level of the codec.
+//
+// All the codecs are compressed natively. The zstd and lz4 encoders have
+// fewer levels than their command line tools, so the level is mapped to the
+// closest one.
+func (Client) Compress(r io.Reader, comp Compression, level int) io.Reader {
+	switch comp {
+	case CompressionNone:
+		return r
+	case CompressionGzip:
+		return compressStream(r, comp, func(w io.Writer) (io.WriteCloser, error) {
+			if level == 0 {
+				level = gzip.DefaultCompression
+			}
+			return gzip.NewWriterLevel(w, level)
+		})
+	case CompressionZstd:
+		return compressStream(r, comp, func(w io.Writer) (io.WriteCloser, error) {
+			opts := []zstd.EOption{}
+			if level > 0 {
+				opts = append(opts, zstd.WithEncoderLevel(
+					zstd.EncoderLevelFromZstd(level),
+				))
+			}
+			return zstd.NewWriter(w, opts...)
+		})
+	case CompressionLZ4:
+		return compressStream(r, comp, func(w io.Writer) (io.WriteCloser, error) {
+			lz4Writer := lz4.NewWriter(w)
+			err := lz4Writer.Apply(
+				lz4.CompressionLevelOption(lz4CompressionLevel(level)),
+			)
+			return lz4Writer, err
+		})
+	default:
+		reader, writer := io.Pipe()
+		writer.CloseWithError(fmt.Errorf(
+			"compression %s can't be used to compress", comp.Value.Key,
+		))
+		return reader
+	}
+}
+
+// decompress returns the content of the reader decompressed with the given
+// codec. The returned reader must be closed by the caller.
+//
+// All the codecs are decompressed natively, the frames written by the zstd
+// and lz4 command line tools of older versions are read as well.
+func decompress(r io.Reader, comp Compression) (io.ReadCloser, error) {
+	switch comp {
+	case CompressionNone:
+		return io.NopCloser(r), nil
+	case CompressionGzip:
+		gzipReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error reading gzip stream: %w", err)
+		}
+		return gzipReader, nil
+	case CompressionZstd:
+		zstdReader, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error reading zstd stream: %w", err)
+		}
+		return zstdReader.IOReadCloser(), nil
+	case CompressionLZ4:
+		return io.NopCloser(lz4.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf(
+			"compression %s can't be used to decompress", comp.Value.Key,
+		)
+	}
+}
+
+// lz4CompressionLevel maps the levels of the lz4 command line tool to the
+// levels of the encoder. Levels 1 and 2 are the fast mode and 3 to 12 are
+// the high compression modes.
+func lz4CompressionLevel(level int) lz4.CompressionLevel {
+	hcLevels := []lz4.CompressionLevel{
+		lz4.Level1, lz4.Level2, lz4.Level3, lz4.Level4, lz4.Level5,
+		lz4.Level6, lz4.Level7, lz4.Level8, lz4.Level9,
+	}
+
+	if level < 3 {
+		return lz4.Fast
+	}
+	return hcLevels[min(level-3, len(hcLevels)-1)]
+}
+
+// compressStream compresses the reader in a goroutine with the writer
+// returned by newWriter.
+func compressStream(
+	r io.Reader, comp Compression,
+	newWriter func(w io.Writer) (io.WriteCloser, error),
+) io.Reader {
+	reader, writer := io.Pipe()
+
+	go func() {
+		compressWriter, err := newWriter(writer)
+		if err != nil {
+			writer.CloseWithError(fmt.Errorf(
+				"error creating %s writer: %w", comp.Value.Key, err,
+			))
+			return
+		}
+
+		// The ReadFrom method of the lz4 writer hides the errors of the reader
+		// so it is not used
+		_, err = io.Copy(struct{ io.Writer }{compressWriter}, r)
+		if err != nil {
+			writer.CloseWithError(fmt.Errorf(
+				"error compressing with %s: %w", comp.Value.Key, err,
+			))
+			compressWriter.Close()
+			return
+		}
+
+		if err := compressWriter.Close(); err != nil {
+			writer.CloseWithError(fmt.Errorf(
+				"error compressing with %s: %w", comp.Value.Key, err,
+			))
+			return
+		}
+
+		writer.Close()
+	}()
+
+	return reader
+}
+
+// pipeCommandContext runs the command with the reader as stdin and returns
+// its stdout, the command is killed if the context is done before it
+// finishes. If the command fails the returned reader fails with its stderr.
+func pipeCommandContext(
+	ctx context.Context, name string, args []string, r io.Reader,
+) io.ReadCloser {
+	reader, writer := io.Pipe()
+
+	errorBuffer := &bytes.Buffer{}
+	cmd := commandContext(ctx, name, args...)
+	cmd.Stdin = r
+	cmd.Stdout = writer
+	cmd.Stderr = errorBuffer
+
+	go func() {
+		defer writer.Close()
+		if err := cmd.Run(); err != nil {
+			// stdin copy errors, like a failed pg_dump, leave stderr empty
+			output := errorBuffer.String()
+			if output == "" {
+				output = err.Error()
+			}
+			writer.CloseWithError(fmt.Errorf("error running %s: %s", name, output))
+		}
+	}()
+
+	return reader
+}
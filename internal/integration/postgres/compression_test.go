@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pierrec/lz4/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressAndDecompress(t *testing.T) {
+	content := strings.Repeat("INSERT INTO users VALUES (1, 'user');\n", 10000)
+
+	tests := []struct {
+		comp   Compression
+		levels []int
+	}{
+		{comp: CompressionNone, levels: []int{0}},
+		{comp: CompressionGzip, levels: []int{0, 1, 9}},
+		{comp: CompressionZstd, levels: []int{0, 1, 3, 19}},
+		{comp: CompressionLZ4, levels: []int{0, 1, 3, 12}},
+	}
+
+	for _, tt := range tests {
+		for _, level := range tt.levels {
+			t.Run(tt.comp.Value.Key, func(t *testing.T) {
+				compressed, err := io.ReadAll(
+					Client{}.Compress(strings.NewReader(content), tt.comp, level),
+				)
+				require.NoError(t, err)
+				if tt.comp != CompressionNone {
+					assert.Less(t, len(compressed), len(content))
+				}
+
+				reader, err := decompress(bytes.NewReader(compressed), tt.comp)
+				require.NoError(t, err)
+				defer reader.Close()
+
+				decompressed, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				assert.Equal(t, content, string(decompressed))
+			})
+		}
+	}
+}
+
+func TestCompressReaderError(t *testing.T) {
+	readErr := errors.New("pg_dump failed")
+
+	for _, comp := range []Compression{
+		CompressionGzip, CompressionZstd, CompressionLZ4,
+	} {
+		t.Run(comp.Value.Key, func(t *testing.T) {
+			r := io.MultiReader(
+				strings.NewReader("partial dump"), iotest.ErrReader(readErr),
+			)
+			_, err := io.ReadAll(Client{}.Compress(r, comp, 0))
+			assert.ErrorIs(t, err, readErr)
+		})
+	}
+}
+
+func TestCompressZip(t *testing.T) {
+	_, err := io.ReadAll(
+		Client{}.Compress(strings.NewReader("dump"), CompressionZip, 0),
+	)
+	assert.ErrorContains(t, err, "can't be used to compress")
+}
+
+func TestLZ4CompressionLevel(t *testing.T) {
+	tests := []struct {
+		level    int
+		expected lz4.CompressionLevel
+	}{
+		{level: 0, expected: lz4.Fast},
+		{level: 2, expected: lz4.Fast},
+		{level: 3, expected: lz4.Level1},
+		{level: 9, expected: lz4.Level7},
+		{level: 11, expected: lz4.Level9},
+		{level: 12, expected: lz4.Level9},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, lz4CompressionLevel(tt.level), tt.level)
+	}
+}
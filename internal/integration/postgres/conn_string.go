@@ -0,0 +1,343 @@
+package postgres
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ConnFields contains the parts of a connection string, so it can be edited
+// field by field.
+type ConnFields struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	DBName   string
+
+	// Options are the other parameters of the connection string in the
+	// key=value format separated by spaces, like sslmode=require.
+	Options string
+}
+
+// connFieldsKeys are the parameters stored in their own field of
+// ConnFields, instead of in the options.
+var connFieldsKeys = []string{"host", "port", "user", "password", "dbname"}
+
+// ParseConnString splits a connection string in the URI or the key/value
+// format into its fields.
+func ParseConnString(connString string) (ConnFields, error) {
+	fields := ConnFields{}
+	options := [][2]string{}
+
+	set := func(key string, value string) {
+		switch key {
+		case "host":
+			fields.Host = value
+		case "port":
+			fields.Port = value
+		case "user":
+			fields.User = value
+		case "password":
+			fields.Password = value
+		case "dbname":
+			fields.DBName = value
+		default:
+			options = append(options, [2]string{key, value})
+		}
+	}
+
+	if isURIConnString(connString) {
+		u, err := url.Parse(connString)
+		if err != nil {
+			return ConnFields{}, fmt.Errorf("error parsing connection string: %w", err)
+		}
+
+		fields.Host, fields.Port = uriHostsAndPorts(u.Host)
+		fields.User = u.User.Username()
+		fields.Password, _ = u.User.Password()
+		fields.DBName = strings.TrimPrefix(u.Path, "/")
+
+		query := u.Query()
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			set(key, query.Get(key))
+		}
+	} else {
+		pairs, err := parseKeyValueConnString(connString)
+		if err != nil {
+			return ConnFields{}, err
+		}
+		for _, pair := range pairs {
+			set(pair[0], pair[1])
+		}
+	}
+
+	for _, option := range options {
+		if fields.Options != "" {
+			fields.Options += " "
+		}
+		fields.Options += option[0] + "=" + quoteConnStringValue(option[1])
+	}
+
+	return fields, nil
+}
+
+// uriHostsAndPorts returns the hosts and ports of the host part of a URI
+// connection string as comma separated lists, like in the key/value format.
+// The ports are empty if no host has a port.
+func uriHostsAndPorts(uriHost string) (string, string) {
+	hosts := []string{}
+	ports := []string{}
+	hasPort := false
+
+	for _, entry := range strings.Split(uriHost, ",") {
+		host, port := entry, ""
+		if i := strings.LastIndex(entry, ":"); i >= 0 && !strings.HasSuffix(entry, "]") {
+			host, port = entry[:i], entry[i+1:]
+		}
+		hosts = append(hosts, strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+		ports = append(ports, port)
+		hasPort = hasPort || port != ""
+	}
+
+	if !hasPort {
+		return strings.Join(hosts, ","), ""
+	}
+	return strings.Join(hosts, ","), strings.Join(ports, ",")
+}
+
+// ConnString returns the connection string of the fields in the key/value
+// format, the empty fields are left to the libpq defaults.
+func (f ConnFields) ConnString() (string, error) {
+	options, err := parseKeyValueConnString(f.Options)
+	if err != nil {
+		return "", fmt.Errorf("error parsing options: %w", err)
+	}
+
+	parts := []string{}
+	for _, pair := range [][2]string{
+		{"host", f.Host},
+		{"port", f.Port},
+		{"user", f.User},
+		{"password", f.Password},
+		{"dbname", f.DBName},
+	} {
+		if pair[1] != "" {
+			parts = append(parts, pair[0]+"="+quoteConnStringValue(pair[1]))
+		}
+	}
+
+	for _, option := range options {
+		if slices.Contains(connFieldsKeys, option[0]) {
+			return "", fmt.Errorf("%s must be set in its own field", option[0])
+		}
+		parts = append(parts, option[0]+"="+quoteConnStringValue(option[1]))
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// parseKeyValueConnString returns the key and value pairs of a key/value
+// connection string, in the same order. Values can be single quoted and use
+// backslashes to escape quotes and backslashes.
+func parseKeyValueConnString(connString string) ([][2]string, error) {
+	pairs := [][2]string{}
+	s := strings.TrimSpace(connString)
+
+	for s != "" {
+		key, rest, ok := strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t\n'") {
+			return nil, fmt.Errorf("invalid connection string near %q", s)
+		}
+		s = strings.TrimLeft(rest, " \t\n")
+
+		value := strings.Builder{}
+		quoted := strings.HasPrefix(s, "'")
+		if quoted {
+			s = s[1:]
+		}
+
+		closed := false
+		i := 0
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) {
+				i++
+				value.WriteByte(s[i])
+				continue
+			}
+			if quoted && c == '\'' {
+				closed = true
+				i++
+				break
+			}
+			if !quoted && (c == ' ' || c == '\t' || c == '\n') {
+				break
+			}
+			value.WriteByte(c)
+		}
+		if quoted && !closed {
+			return nil, fmt.Errorf("unterminated quoted value of %s", key)
+		}
+
+		pairs = append(pairs, [2]string{key, value.String()})
+		s = strings.TrimLeft(s[i:], " \t\n")
+	}
+
+	return pairs, nil
+}
+
+// quoteConnStringValue returns the value quoted for a key/value connection
+// string if it is needed.
+func quoteConnStringValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n'\\") {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// connStringParamRegex returns the regex that matches a parameter of a key
+// value connection string, with the value in the second group.
+func connStringParamRegex(key string) *regexp.Regexp {
+	return regexp.MustCompile(
+		`(^|\s)` + key + `\s*=\s*('(?:[^'\\]|\\.)*'|\S+)`,
+	)
+}
+
+// connStringParam returns the unquoted value of a parameter of a key value
+// connection string, empty if it is not set.
+func connStringParam(connString string, regex *regexp.Regexp) string {
+	match := regex.FindStringSubmatch(connString)
+	if match == nil {
+		return ""
+	}
+
+	value := match[2]
+	if strings.HasPrefix(value, "'") {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "'"), "'")
+		value = strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(value)
+	}
+	return value
+}
+
+// setConnStringParam returns the key value connection string with the
+// parameter set to value, replacing it if it is already set.
+func setConnStringParam(
+	connString string, key string, regex *regexp.Regexp, value string,
+) string {
+	quoted := "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+
+	if regex.MatchString(connString) {
+		return regex.ReplaceAllStringFunc(connString, func(match string) string {
+			prefix := match[:len(match)-len(strings.TrimLeft(match, " \t\n"))]
+			return prefix + key + "=" + quoted
+		})
+	}
+
+	return strings.TrimSpace(connString + " " + key + "=" + quoted)
+}
+
+// isURIConnString returns true if the connection string uses the URI format.
+func isURIConnString(connString string) bool {
+	return strings.HasPrefix(connString, "postgresql://") ||
+		strings.HasPrefix(connString, "postgres://")
+}
+
+// removeConnStringParam returns the key value connection string without the
+// parameter.
+func removeConnStringParam(connString string, regex *regexp.Regexp) string {
+	return strings.TrimSpace(regex.ReplaceAllString(connString, "$1"))
+}
+
+var connStringPasswordRegex = connStringParamRegex("password")
+
+// hidePassword returns the connection string without the password and the
+// environment of the command that passes the password in PGPASSWORD, so it
+// is not visible in the process list.
+//
+// If the connection string has no password, the environment is nil and the
+// command inherits the environment of the process.
+func hidePassword(connString string) (string, []string) {
+	password := ""
+
+	if isURIConnString(connString) {
+		u, err := url.Parse(connString)
+		if err != nil {
+			// libpq may still accept it, e.g. with IPv6 hosts in a multi-host
+			// string, so the password is removed without parsing it
+			connString, password = hideUnparsedURIPassword(connString)
+			if password == "" {
+				return connString, nil
+			}
+			return connString, append(os.Environ(), "PGPASSWORD="+password)
+		}
+
+		query := u.Query()
+		if p, ok := u.User.Password(); ok {
+			password = p
+			u.User = url.User(u.User.Username())
+		}
+		if query.Has("password") {
+			password = query.Get("password")
+			query.Del("password")
+			u.RawQuery = query.Encode()
+		}
+		connString = u.String()
+	} else {
+		password = connStringParam(connString, connStringPasswordRegex)
+		connString = removeConnStringParam(connString, connStringPasswordRegex)
+	}
+
+	if password == "" {
+		return connString, nil
+	}
+	return connString, append(os.Environ(), "PGPASSWORD="+password)
+}
+
+var uriQueryPasswordRegex = regexp.MustCompile(`([?&])password=([^&#]*)&?`)
+
+// hideUnparsedURIPassword returns the URI connection string without the
+// password of the user info or the query, and the unescaped password.
+func hideUnparsedURIPassword(connString string) (string, string) {
+	password := ""
+
+	scheme, rest, _ := strings.Cut(connString, "://")
+	authorityEnd := strings.IndexAny(rest, "/?")
+	if authorityEnd < 0 {
+		authorityEnd = len(rest)
+	}
+	authority := rest[:authorityEnd]
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		if user, p, ok := strings.Cut(authority[:at], ":"); ok {
+			password = unescapeURIValue(p)
+			rest = user + rest[at:]
+		}
+	}
+
+	if match := uriQueryPasswordRegex.FindStringSubmatch(rest); match != nil {
+		password = unescapeURIValue(match[2])
+		rest = uriQueryPasswordRegex.ReplaceAllString(rest, "$1")
+		rest = strings.TrimRight(rest, "?&")
+	}
+
+	return scheme + "://" + rest, password
+}
+
+// unescapeURIValue returns the percent-decoded value, or the value as is if
+// it is not correctly encoded.
+func unescapeURIValue(value string) string {
+	unescaped, err := url.PathUnescape(value)
+	if err != nil {
+		return value
+	}
+	return unescaped
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"errors"
+	"os"
+)
+
+// ConnectionParams contains the settings needed to connect to a server
+// besides its connection string.
+type ConnectionParams struct {
+	SSHTunnel SSHTunnelParams
+	TLS       TLSParams
+}
+
+// Connection holds the resources used to connect to a server, like the SSH
+// tunnel and the TLS files, while pg_dump, psql or pg_restore run.
+type Connection struct {
+	// ConnString is the connection string to use while the connection is
+	// open.
+	ConnString string
+
+	tunnel *SSHTunnel
+	tlsDir string
+}
+
+// OpenConnection writes the TLS files and opens the SSH tunnel of the
+// server, if they are set. The returned connection must be closed when it is
+// no longer used, which removes the TLS files.
+func (c Client) OpenConnection(
+	connString string, params ConnectionParams,
+) (*Connection, error) {
+	connString, tlsDir, err := writeTLSFiles(connString, params.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnel, err := c.OpenSSHTunnel(connString, params.SSHTunnel)
+	if err != nil {
+		if tlsDir != "" {
+			_ = os.RemoveAll(tlsDir)
+		}
+		return nil, err
+	}
+
+	return &Connection{
+		ConnString: tunnel.ConnString,
+		tunnel:     tunnel,
+		tlsDir:     tlsDir,
+	}, nil
+}
+
+// Close closes the SSH tunnel and removes the TLS files.
+func (c *Connection) Close() error {
+	err := c.tunnel.Close()
+	if c.tlsDir != "" {
+		err = errors.Join(err, os.RemoveAll(c.tlsDir))
+	}
+	return err
+}
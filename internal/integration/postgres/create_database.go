@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CreateDatabaseParams contains the parameters for the CREATE DATABASE command
+type CreateDatabaseParams struct {
+	// Name of the new database.
+	Name string
+
+	// Owner (OWNER): Role that will own the new database. Empty to use the
+	// role of the connection.
+	Owner string
+
+	// Template (TEMPLATE): Database used as template. Empty to use the server
+	// default (template1), template0 is recommended for restores because it
+	// has no local additions.
+	Template string
+}
+
+// CreateDatabase creates a new database using psql connected to a maintenance
+// database, like postgres, of the same server.
+//
+// The identifiers are passed as psql variables so they are always quoted.
+func (Client) CreateDatabase(
+	ctx context.Context, version PGVersion, connString string, params CreateDatabaseParams,
+) error {
+	if params.Name == "" {
+		return fmt.Errorf("database name is required")
+	}
+
+	connString, env := hidePassword(connString)
+	args := []string{
+		connString, "-v", "ON_ERROR_STOP=1", "-v", "dbname=" + params.Name,
+	}
+	query := `CREATE DATABASE :"dbname"`
+
+	if params.Owner != "" {
+		args = append(args, "-v", "owner="+params.Owner)
+		query += ` OWNER :"owner"`
+	}
+	if params.Template != "" {
+		args = append(args, "-v", "template="+params.Template)
+		query += ` TEMPLATE :"template"`
+	}
+
+	// Variables are not interpolated in -c commands, only in scripts
+	cmd := commandContext(ctx, version.Value.PSQL, args...)
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(query + ";\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"error creating database %s with psql v%s: %s",
+			params.Name, version.Value.Version, output,
+		)
+	}
+
+	return nil
+}
+
+// DropDatabase drops the database, if it exists, using psql connected to a
+// maintenance database of the same server. Other sessions connected to the
+// database are terminated.
+func (Client) DropDatabase(
+	ctx context.Context, version PGVersion, connString string, name string,
+) error {
+	connString, env := hidePassword(connString)
+	cmd := commandContext(
+		ctx, version.Value.PSQL, connString, "-v", "ON_ERROR_STOP=1",
+		"-v", "dbname="+name,
+	)
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(
+		`DROP DATABASE IF EXISTS :"dbname" WITH (FORCE);` + "\n",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"error dropping database %s with psql v%s: %s",
+			name, version.Value.Version, output,
+		)
+	}
+
+	return nil
+}
+
+var connStringDBNameRegex = connStringParamRegex("dbname")
+
+// ReplaceDatabaseName returns the connection string with the database name
+// replaced, it supports both the URI and the key/value formats.
+func ReplaceDatabaseName(connString string, dbName string) (string, error) {
+	if isURIConnString(connString) {
+		u, err := url.Parse(connString)
+		if err != nil {
+			return "", fmt.Errorf("error parsing connection string: %w", err)
+		}
+		u.Path = "/" + dbName
+		u.RawPath = ""
+		return u.String(), nil
+	}
+
+	return setConnStringParam(
+		connString, "dbname", connStringDBNameRegex, dbName,
+	), nil
+}
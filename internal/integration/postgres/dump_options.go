@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DumpOptions contains the less common pg_dump options of a backup. They are
+// stored as a JSON document so new options don't need a column each.
+type DumpOptions struct {
+	// NoOwner (--no-owner): Do not output commands to set ownership of objects
+	// to match the original database.
+	NoOwner bool `json:"no_owner,omitempty"`
+
+	// NoPrivileges (--no-privileges): Prevent dumping of access privileges
+	// (grant/revoke commands).
+	NoPrivileges bool `json:"no_privileges,omitempty"`
+
+	// NoBlobs (--no-blobs): Exclude large objects from the dump.
+	NoBlobs bool `json:"no_blobs,omitempty"`
+
+	// LockWaitTimeout (--lock-wait-timeout): Do not wait forever to acquire
+	// shared table locks at the beginning of the dump, fail if a table can't
+	// be locked within this timeout, like 30s or 5min. Empty waits forever.
+	LockWaitTimeout string `json:"lock_wait_timeout,omitempty"`
+
+	// Role (--role): Role name used to create the dump, pg_dump issues a SET
+	// ROLE after connecting.
+	Role string `json:"role,omitempty"`
+
+	// QuoteAllIdentifiers (--quote-all-identifiers): Force quoting of all
+	// identifiers.
+	QuoteAllIdentifiers bool `json:"quote_all_identifiers,omitempty"`
+
+	// NoPublications (--no-publications): Do not dump publications.
+	NoPublications bool `json:"no_publications,omitempty"`
+
+	// NoSubscriptions (--no-subscriptions): Do not dump subscriptions.
+	NoSubscriptions bool `json:"no_subscriptions,omitempty"`
+
+	// SerializableDeferrable (--serializable-deferrable): Use a serializable
+	// transaction for the dump, to ensure that the snapshot used is consistent
+	// with later database states.
+	SerializableDeferrable bool `json:"serializable_deferrable,omitempty"`
+
+	// Extensions (--extension): Dump only extensions matching these patterns,
+	// requires pg_dump 14 or newer.
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+var lockWaitTimeoutRegex = regexp.MustCompile(`^\d+(ms|s|min|h|d)?$`)
+
+// ParseDumpOptions returns the dump options stored in the JSON document and
+// validates them. An empty document has no options.
+func (Client) ParseDumpOptions(data []byte) (DumpOptions, error) {
+	options := DumpOptions{}
+	if len(data) == 0 {
+		return options, nil
+	}
+
+	if err := json.Unmarshal(data, &options); err != nil {
+		return DumpOptions{}, fmt.Errorf("error parsing dump options: %w", err)
+	}
+
+	return options, options.Validate()
+}
+
+// Validate returns an error if an option has an invalid value.
+func (o DumpOptions) Validate() error {
+	if o.LockWaitTimeout != "" && !lockWaitTimeoutRegex.MatchString(o.LockWaitTimeout) {
+		return fmt.Errorf(
+			"invalid lock wait timeout %q, use a number with an optional unit "+
+				"(ms, s, min, h, d)", o.LockWaitTimeout,
+		)
+	}
+
+	if strings.TrimSpace(o.Role) != o.Role {
+		return fmt.Errorf("role can't start or end with spaces")
+	}
+
+	for _, pattern := range o.Extensions {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("extension patterns can't be empty")
+		}
+	}
+
+	return nil
+}
+
+// args returns the pg_dump arguments of the options.
+func (o DumpOptions) args() []string {
+	args := []string{}
+	if o.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if o.NoPrivileges {
+		args = append(args, "--no-privileges")
+	}
+	if o.NoBlobs {
+		args = append(args, "--no-blobs")
+	}
+	if o.LockWaitTimeout != "" {
+		args = append(args, "--lock-wait-timeout="+o.LockWaitTimeout)
+	}
+	if o.Role != "" {
+		args = append(args, "--role="+o.Role)
+	}
+	if o.QuoteAllIdentifiers {
+		args = append(args, "--quote-all-identifiers")
+	}
+	if o.NoPublications {
+		args = append(args, "--no-publications")
+	}
+	if o.NoSubscriptions {
+		args = append(args, "--no-subscriptions")
+	}
+	if o.SerializableDeferrable {
+		args = append(args, "--serializable-deferrable")
+	}
+	for _, pattern := range o.Extensions {
+		args = append(args, "--extension="+pattern)
+	}
+
+	return args
+}
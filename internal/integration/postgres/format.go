@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+
+	"github.com/orsinium-labs/enum"
+)
+
+type format struct {
+	Key  string
+	Name string
+	// Extension is the extension of the uncompressed artifact, directory
+	// archives are stored as a tar file.
+	Extension string
+	// ZipEntry is the name of the file (or directory) that holds the dump
+	// inside the legacy ZIP artifacts restored by RestoreZip.
+	ZipEntry string
+}
+
+type DumpFormat enum.Member[format]
+
+var (
+	FormatPlain = DumpFormat{format{
+		Key:       "plain",
+		Name:      "Plain SQL",
+		Extension: ".sql",
+		ZipEntry:  "dump.sql",
+	}}
+	FormatCustom = DumpFormat{format{
+		Key:       "custom",
+		Name:      "Custom archive",
+		Extension: ".dump",
+		ZipEntry:  "dump.dump",
+	}}
+	FormatDirectory = DumpFormat{format{
+		Key:       "directory",
+		Name:      "Directory archive",
+		Extension: ".tar",
+		ZipEntry:  "dump",
+	}}
+
+	DumpFormats = []DumpFormat{FormatPlain, FormatCustom, FormatDirectory}
+)
+
+// ParseDumpFormat returns the DumpFormat enum member for the given format key.
+func (Client) ParseDumpFormat(key string) (DumpFormat, error) {
+	switch key {
+	case FormatPlain.Value.Key:
+		return FormatPlain, nil
+	case FormatCustom.Value.Key:
+		return FormatCustom, nil
+	case FormatDirectory.Value.Key:
+		return FormatDirectory, nil
+	default:
+		return DumpFormat{}, fmt.Errorf("dump format not allowed: %s", key)
+	}
+}
+
+// IsArchive returns true if the format must be restored using pg_restore
+// instead of psql.
+func (f DumpFormat) IsArchive() bool {
+	return f == FormatCustom || f == FormatDirectory
+}
+
+// detectZipDumpFormat looks for the known dump entries inside a legacy ZIP
+// artifact and returns the format of the dump.
+func detectZipDumpFormat(zipReader *zip.Reader) (DumpFormat, error) {
+	dirPrefix := FormatDirectory.Value.ZipEntry + "/"
+
+	for _, file := range zipReader.File {
+		switch {
+		case file.Name == FormatPlain.Value.ZipEntry:
+			return FormatPlain, nil
+		case file.Name == FormatCustom.Value.ZipEntry:
+			return FormatCustom, nil
+		case strings.HasPrefix(file.Name, dirPrefix):
+			return FormatDirectory, nil
+		}
+	}
+
+	return DumpFormat{}, fmt.Errorf("no dump found in ZIP file")
+}
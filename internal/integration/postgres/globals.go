@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// DumpGlobalsParams contains the parameters for the pg_dumpall command
+type DumpGlobalsParams struct {
+	// RolesOnly (--roles-only): Dump only roles, no tablespaces. When false
+	// the command runs with --globals-only, which dumps roles and tablespaces.
+	RolesOnly bool
+}
+
+// DumpGlobals runs the pg_dumpall command to dump the cluster-wide objects
+// (roles, their grants and tablespaces) that pg_dump does not include, and
+// returns the dump as an io.Reader.
+//
+// The output is a plain SQL script, so it can be restored as a plain dump.
+// pg_dumpall is killed if the context is done before it finishes.
+func (Client) DumpGlobals(
+	ctx context.Context, version PGVersion, connString string,
+	params ...DumpGlobalsParams,
+) io.Reader {
+	pickedParams := DumpGlobalsParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
+
+	connString, env := hidePassword(connString)
+	args := []string{"--dbname=" + connString}
+	if pickedParams.RolesOnly {
+		args = append(args, "--roles-only")
+	} else {
+		args = append(args, "--globals-only")
+	}
+
+	reader, writer := io.Pipe()
+
+	errorBuffer := &bytes.Buffer{}
+	cmd := commandContext(ctx, version.Value.PGDumpAll, args...)
+	cmd.Env = env
+	cmd.Stdout = writer
+	cmd.Stderr = errorBuffer
+
+	go func() {
+		defer writer.Close()
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				writer.CloseWithError(ctx.Err())
+				return
+			}
+			writer.CloseWithError(fmt.Errorf(
+				"error running pg_dumpall v%s: %s",
+				version.Value.Version, errorBuffer.String(),
+			))
+		}
+	}()
+
+	return reader
+}
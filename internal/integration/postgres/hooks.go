@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// HookParams contains the SQL and the shell command of a backup hook. Both
+// are optional, the SQL runs first.
+type HookParams struct {
+	// SQL is run using psql in a single transaction, for example CHECKPOINT
+	// or REFRESH MATERIALIZED VIEW.
+	SQL string
+
+	// Command is run using sh -c in the PG Back Web server. The connection
+	// string, without the password, is available in the PBW_CONNECTION_STRING
+	// environment variable and the password in PGPASSWORD.
+	Command string
+
+	// Timeout is the maximum duration of the SQL and the command, each one
+	// has its own timeout.
+	Timeout time.Duration
+}
+
+// IsEnabled returns true if the hook has SQL or a command to run.
+func (p HookParams) IsEnabled() bool {
+	return strings.TrimSpace(p.SQL) != "" || strings.TrimSpace(p.Command) != ""
+}
+
+// RunHook runs the SQL and the command of the hook and returns their combined
+// output. It stops at the first one that fails, exceeds the timeout or is
+// running when the context is done.
+func (Client) RunHook(
+	ctx context.Context, version PGVersion, connString string,
+	params HookParams,
+) (string, error) {
+	connString, env := hidePassword(connString)
+	if env == nil {
+		env = os.Environ()
+	}
+
+	output := &strings.Builder{}
+
+	if strings.TrimSpace(params.SQL) != "" {
+		out, err := runHookCommand(
+			ctx, params.Timeout, env, params.SQL, version.Value.PSQL, connString, "-X",
+			"--single-transaction", "-v", "ON_ERROR_STOP=1", "-f", "-",
+		)
+		output.WriteString(out)
+		if err != nil {
+			return output.String(), fmt.Errorf("error running hook SQL: %w", err)
+		}
+	}
+
+	if strings.TrimSpace(params.Command) != "" {
+		out, err := runHookCommand(
+			ctx, params.Timeout, append(env, "PBW_CONNECTION_STRING="+connString), "",
+			"sh", "-c", params.Command,
+		)
+		output.WriteString(out)
+		if err != nil {
+			return output.String(), fmt.Errorf("error running hook command: %w", err)
+		}
+	}
+
+	return output.String(), nil
+}
+
+// runHookCommand runs the command with the stdin content and returns its
+// combined output, the command is killed if it doesn't finish within the
+// timeout.
+func runHookCommand(
+	ctx context.Context, timeout time.Duration, env []string, stdin string,
+	name string, args ...string,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := commandContext(ctx, name, args...)
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(stdin)
+
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return string(output), fmt.Errorf("timed out after %s", timeout)
+	}
+	if ctx.Err() != nil {
+		return string(output), ctx.Err()
+	}
+	if err != nil {
+		return string(output), err
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,286 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DumpObject is an object created by a dump, like a schema, a table, a
+// function or an extension.
+type DumpObject struct {
+	// Type is the kind of object as written by pg_dump, for example TABLE or
+	// FUNCTION. Roles and tablespaces of globals dumps are ROLE and
+	// TABLESPACE.
+	Type string `json:"type"`
+	// Schema is the schema of the object, empty for objects without schema.
+	Schema string `json:"schema"`
+	// Name is the name of the object, functions include their arguments.
+	Name string `json:"name"`
+	// Owner is the role that owns the object, empty if it is not recorded.
+	Owner string `json:"owner"`
+	// DataSize is the approximate size in bytes of the data of a table,
+	// measured as the SQL text that loads it.
+	DataSize int64 `json:"data_size"`
+}
+
+// InspectDump returns the objects created by the dump read from r,
+// decompressing it with the codec that was used to create it.
+//
+// Plain dumps are scanned as they are streamed, custom and directory archives
+// are converted to a plain script with pg_restore --file=- first, so the
+// whole artifact is read to measure the data sizes.
+//
+// Legacy ZIP artifacts (CompressionZip) can't be streamed, use
+// InspectDumpZip.
+//
+// pg_restore is killed if the context is done before it finishes.
+func (Client) InspectDump(
+	ctx context.Context, version PGVersion, r io.Reader,
+	dumpFormat DumpFormat, comp Compression,
+) ([]DumpObject, error) {
+	var objects []DumpObject
+	err := handleDump(r, dumpFormat, comp, inspectHandler(ctx, version, &objects))
+	return objects, err
+}
+
+// InspectDumpZip returns the objects created by the dump found inside the
+// legacy ZIP artifact read from r.
+//
+// pg_restore is killed if the context is done before it finishes.
+func (Client) InspectDumpZip(
+	ctx context.Context, version PGVersion, r io.ReaderAt, size int64,
+) ([]DumpObject, error) {
+	var objects []DumpObject
+	err := handleZipDump(r, size, inspectHandler(ctx, version, &objects))
+	return objects, err
+}
+
+// inspectHandler returns the handler that stores the objects created by a
+// dump into objects.
+func inspectHandler(
+	ctx context.Context, version PGVersion, objects *[]DumpObject,
+) dumpHandler {
+	scanScript := func(script io.ReadCloser) error {
+		defer script.Close()
+
+		found, err := scanDump(script)
+		if err != nil {
+			return err
+		}
+
+		*objects = found
+		return nil
+	}
+
+	return dumpHandler{
+		stream: func(r io.Reader, dumpFormat DumpFormat) error {
+			if !dumpFormat.IsArchive() {
+				return scanScript(io.NopCloser(r))
+			}
+			return scanScript(pipeCommandContext(
+				ctx, version.Value.PGRestore,
+				[]string{"--file=-", "--format=" + dumpFormat.Value.Key},
+				r,
+			))
+		},
+		directory: func(dir string) error {
+			return scanScript(pipeCommandContext(
+				ctx, version.Value.PGRestore,
+				[]string{"--file=-", "--format=" + FormatDirectory.Value.Key, dir},
+				nil,
+			))
+		},
+	}
+}
+
+// modifierTypes are the entries of a dump that modify other objects instead
+// of creating new ones.
+var modifierTypes = []string{
+	"COMMENT", "ACL", "DEFAULT ACL", "SECURITY LABEL", "DEFAULT",
+	"SEQUENCE SET", "SEQUENCE OWNED BY",
+}
+
+// scanDump reads a plain SQL script written by pg_dump or pg_dumpall and
+// returns the objects it creates.
+//
+// Objects are found in the comment written by pg_dump before each of them:
+//
+//	-- Name: users; Type: TABLE; Schema: public; Owner: postgres
+//
+// The data of a table starts with a similar "-- Data for Name:" comment and
+// its size is the number of bytes until the next comment of an object.
+// pg_dumpall doesn't write these comments, its roles and tablespaces are
+// found in their CREATE statements.
+func scanDump(r io.Reader) ([]DumpObject, error) {
+	objects := []DumpObject{}
+	tables := map[string]int{}
+
+	// Index in objects of the table whose data is being read, -1 if none
+	dataIndex := -1
+	inCopy := false
+
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var line []byte
+	for {
+		var size int
+		var err error
+		line, size, err = readScriptLine(reader, line[:0])
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("error reading dump: %w", err)
+		}
+		if size == 0 && errors.Is(err, io.EOF) {
+			break
+		}
+
+		switch {
+		case inCopy:
+			// COPY rows are data, even if they look like a comment
+			if bytes.Equal(bytes.TrimRight(line, "\r\n"), []byte(`\.`)) {
+				inCopy = false
+			}
+
+		case bytes.HasPrefix(line, []byte("COPY ")):
+			inCopy = bytes.HasSuffix(
+				bytes.TrimRight(line, "\r\n"), []byte("FROM stdin;"),
+			)
+
+		case bytes.HasPrefix(line, []byte("-- Data for Name: ")):
+			dataIndex = -1
+			object, ok := parseObjectComment(
+				string(line[len("-- Data for Name: "):]),
+			)
+			if !ok {
+				break
+			}
+
+			key := object.Schema + "." + object.Name
+			index, ok := tables[key]
+			if !ok {
+				object.Type = "TABLE"
+				objects = append(objects, object)
+				index = len(objects) - 1
+				tables[key] = index
+			}
+			dataIndex = index
+			// The comment is not part of the data
+			continue
+
+		case bytes.HasPrefix(line, []byte("-- Name: ")):
+			dataIndex = -1
+			object, ok := parseObjectComment(string(line[len("-- Name: "):]))
+			if !ok {
+				break
+			}
+
+			// Comments, ACLs and sequence values modify other objects
+			if slices.Contains(modifierTypes, object.Type) {
+				break
+			}
+
+			objects = append(objects, object)
+			if object.Type == "TABLE" {
+				tables[object.Schema+"."+object.Name] = len(objects) - 1
+			}
+
+		case dataIndex == -1:
+			if object, ok := parseGlobalStatement(string(line)); ok {
+				objects = append(objects, object)
+			}
+		}
+
+		if dataIndex != -1 {
+			objects[dataIndex].DataSize += int64(size)
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	return objects, nil
+}
+
+// readScriptLine appends the beginning of the next line to buf, enough to
+// parse the comments and statements, and returns it with the full size of
+// the line in bytes, so very long lines like COPY rows are never held in
+// memory.
+func readScriptLine(reader *bufio.Reader, buf []byte) ([]byte, int, error) {
+	line, err := reader.ReadSlice('\n')
+	size := len(line)
+	start := append(buf, line...)
+
+	for errors.Is(err, bufio.ErrBufferFull) {
+		line, err = reader.ReadSlice('\n')
+		size += len(line)
+	}
+
+	return start, size, err
+}
+
+// parseObjectComment parses the rest of a pg_dump object comment after the
+// "Name: " prefix, for example:
+//
+//	users; Type: TABLE; Schema: public; Owner: postgres
+func parseObjectComment(comment string) (DumpObject, bool) {
+	comment = strings.TrimRight(comment, "\r\n")
+
+	// Names may contain "; ", the fields after it never do
+	name, rest, ok := cutLast(comment, "; Type: ")
+	if !ok {
+		return DumpObject{}, false
+	}
+
+	object := DumpObject{Name: name}
+	for i, field := range strings.Split(rest, "; ") {
+		if i == 0 {
+			object.Type = field
+			continue
+		}
+
+		key, value, _ := strings.Cut(field, ": ")
+		if value == "-" {
+			value = ""
+		}
+		switch key {
+		case "Schema":
+			object.Schema = value
+		case "Owner":
+			object.Owner = value
+		}
+	}
+
+	return object, true
+}
+
+// parseGlobalStatement returns the role or tablespace created by a
+// statement of a pg_dumpall script.
+func parseGlobalStatement(line string) (DumpObject, bool) {
+	for _, t := range []string{"ROLE", "TABLESPACE"} {
+		rest, ok := strings.CutPrefix(line, "CREATE "+t+" ")
+		if !ok {
+			continue
+		}
+
+		name := strings.TrimRight(strings.TrimSpace(rest), ";")
+		if t == "TABLESPACE" {
+			name, _, _ = strings.Cut(name, " OWNER ")
+		}
+		return DumpObject{Type: t, Name: strings.Trim(name, `"`)}, true
+	}
+
+	return DumpObject{}, false
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
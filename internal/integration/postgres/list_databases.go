@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ListDatabases returns the names of the databases of the server that accept
+// connections, the template databases are skipped.
+//
+// The rows are separated by a zero byte so names with new lines are
+// returned as they are.
+func (Client) ListDatabases(
+	ctx context.Context, version PGVersion, connString string,
+) ([]string, error) {
+	connString, env := hidePassword(connString)
+
+	errorBuffer := &bytes.Buffer{}
+	cmd := commandContext(
+		ctx, version.Value.PSQL, connString, "-X", "--no-align", "--tuples-only",
+		"--record-separator-zero", "-v", "ON_ERROR_STOP=1", "-c",
+		"SELECT datname FROM pg_database "+
+			"WHERE datallowconn AND NOT datistemplate ORDER BY datname",
+	)
+	cmd.Env = env
+	cmd.Stderr = errorBuffer
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error listing databases with psql v%s: %s",
+			version.Value.Version, errorBuffer.String(),
+		)
+	}
+
+	rows := strings.TrimSuffix(string(output), "\n")
+	names := []string{}
+	for _, name := range strings.Split(rows, "\x00") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
@@ -3,10 +3,16 @@ package postgres
 import (
 	"archive/zip"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 	"github.com/orsinium-labs/enum"
@@ -18,83 +24,113 @@ import (
 	https://www.postgresql.org/support/versioning/
 
 	Backing up a database from an old unsupported version should not be allowed.
+
+	Versions are not hardcoded, they are registered at startup from the client
+	binaries installed in the system (see DiscoverVersions) or declared in the
+	configuration, so a new major version is supported as soon as its binaries
+	are installed.
 */
 
 type version struct {
-	Version string
-	PGDump  string
-	PSQL    string
+	Version      string
+	PGDump       string
+	PSQL         string
+	PGRestore    string
+	PGDumpAll    string
+	PGBaseBackup string
+	PGReceiveWAL string
 }
 
 type PGVersion enum.Member[version]
 
-var (
-	PG13 = PGVersion{version{
-		Version: "13",
-		PGDump:  "/usr/lib/postgresql/13/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/13/bin/psql",
-	}}
-	PG14 = PGVersion{version{
-		Version: "14",
-		PGDump:  "/usr/lib/postgresql/14/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/14/bin/psql",
-	}}
-	PG15 = PGVersion{version{
-		Version: "15",
-		PGDump:  "/usr/lib/postgresql/15/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/15/bin/psql",
-	}}
-	PG16 = PGVersion{version{
-		Version: "16",
-		PGDump:  "/usr/lib/postgresql/16/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/16/bin/psql",
-	}}
-	PG17 = PGVersion{version{
-		Version: "17",
-		PGDump:  "/usr/lib/postgresql/17/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/17/bin/psql",
-	}}
-
-	PGVersions = []PGVersion{PG13, PG14, PG15, PG16, PG17}
-)
+type Client struct {
+	// versions are the registered versions sorted from oldest to newest.
+	versions []PGVersion
+}
 
-type Client struct{}
+// New creates a new PostgreSQL client using the given registered versions,
+// at least one version is required.
+func New(versions []PGVersion) (*Client, error) {
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no PostgreSQL client binaries found")
+	}
+
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b PGVersion) int {
+		return a.major() - b.major()
+	})
+
+	return &Client{versions: sorted}, nil
+}
 
-func New() *Client {
-	return &Client{}
+// Versions returns the registered versions sorted from oldest to newest.
+func (c Client) Versions() []PGVersion {
+	return slices.Clone(c.versions)
 }
 
-// ParseVersion returns the PGVersion enum member for the given PostgreSQL
+// ParseVersion returns the registered PGVersion for the given PostgreSQL
 // version as a string.
-func (Client) ParseVersion(version string) (PGVersion, error) {
-	switch version {
-	case "13":
-		return PG13, nil
-	case "14":
-		return PG14, nil
-	case "15":
-		return PG15, nil
-	case "16":
-		return PG16, nil
-	case "17":
-		return PG17, nil
-	default:
-		return PGVersion{}, fmt.Errorf("pg version not allowed: %s", version)
+func (c Client) ParseVersion(version string) (PGVersion, error) {
+	for _, v := range c.versions {
+		if v.Value.Version == version {
+			return v, nil
+		}
 	}
+
+	return PGVersion{}, fmt.Errorf("pg version not allowed: %s", version)
 }
 
-// Test tests the connection to the PostgreSQL database
-func (Client) Test(version PGVersion, connString string) error {
-	cmd := exec.Command(version.Value.PSQL, connString, "-c", "SELECT 1;")
-	output, err := cmd.CombinedOutput()
+// IsNewerThan returns true if the version is newer than the other version.
+func (v PGVersion) IsNewerThan(other PGVersion) bool {
+	return v.major() > other.major()
+}
+
+// major returns the major version number, or 0 if the version is invalid.
+func (v PGVersion) major() int {
+	major, _ := strconv.Atoi(v.Value.Version)
+	return major
+}
+
+// Test tests the connection to the PostgreSQL database and returns the
+// version of the server, detected from its server_version_num setting.
+//
+// The test uses the newest psql available because it can connect to any
+// supported server version.
+func (c Client) Test(ctx context.Context, connString string) (PGVersion, error) {
+	latest := c.versions[len(c.versions)-1]
+
+	connString, env := hidePassword(connString)
+
+	// psqlrc files and notices must not change the parsed output
+	errorBuffer := &bytes.Buffer{}
+	cmd := commandContext(
+		ctx, latest.Value.PSQL, connString, "-X", "-t", "-A",
+		"-c", "SHOW server_version_num;",
+	)
+	cmd.Env = env
+	cmd.Stderr = errorBuffer
+	output, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf(
+		return PGVersion{}, fmt.Errorf(
 			"error running psql test v%s: %s",
-			version.Value.Version, output,
+			latest.Value.Version, errorBuffer.String(),
 		)
 	}
 
-	return nil
+	versionNum, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return PGVersion{}, fmt.Errorf(
+			"error parsing server_version_num %q: %w", output, err,
+		)
+	}
+
+	// server_version_num is major * 10000 + minor since PostgreSQL 10
+	version, err := c.ParseVersion(strconv.Itoa(versionNum / 10000))
+	if err != nil {
+		return PGVersion{}, fmt.Errorf("unsupported server version: %w", err)
+	}
+
+	return version, nil
 }
 
 // DumpParams contains the parameters for the pg_dump command
@@ -127,47 +163,141 @@ type DumpParams struct {
 
 	// NoComments (--no-comments): Do not dump comments.
 	NoComments bool
-}
 
-// Dump runs the pg_dump command with the given parameters. It returns the SQL
-// dump as an io.Reader.
-func (Client) Dump(
-	version PGVersion, connString string, params ...DumpParams,
-) io.Reader {
-	pickedParams := DumpParams{}
-	if len(params) > 0 {
-		pickedParams = params[0]
-	}
+	// Format (--format): Selects the format of the output. Plain SQL scripts
+	// are restored using psql, custom and directory archives are restored using
+	// pg_restore. Defaults to plain.
+	Format DumpFormat
+
+	// Jobs (--jobs): Run the dump in parallel by dumping this number of tables
+	// simultaneously. Only used with the directory format and when greater
+	// than 1.
+	Jobs int
+
+	// Schemas (--schema): Dump only schemas matching these patterns.
+	Schemas []string
+
+	// ExcludeSchemas (--exclude-schema): Do not dump any schemas matching these
+	// patterns.
+	ExcludeSchemas []string
+
+	// Tables (--table): Dump only tables (and views, sequences, etc.) matching
+	// these patterns.
+	Tables []string
+
+	// ExcludeTables (--exclude-table): Do not dump any tables matching these
+	// patterns.
+	ExcludeTables []string
 
+	// ExcludeTableData (--exclude-table-data): Do not dump data for any tables
+	// matching these patterns. The definition of the tables is still dumped.
+	ExcludeTableData []string
+
+	// Options are the less common pg_dump options.
+	Options DumpOptions
+}
+
+// dumpArgs returns the pg_dump arguments for the given parameters.
+func dumpArgs(connString string, params DumpParams) []string {
 	args := []string{connString}
-	if pickedParams.DataOnly {
+	if params.DataOnly {
 		args = append(args, "--data-only")
 	}
-	if pickedParams.SchemaOnly {
+	if params.SchemaOnly {
 		args = append(args, "--schema-only")
 	}
-	if pickedParams.Clean {
+	if params.Clean {
 		args = append(args, "--clean")
 	}
-	if pickedParams.IfExists {
+	if params.IfExists {
 		args = append(args, "--if-exists")
 	}
-	if pickedParams.Create {
+	if params.Create {
 		args = append(args, "--create")
 	}
-	if pickedParams.NoComments {
+	if params.NoComments {
 		args = append(args, "--no-comments")
 	}
+	if params.Format.IsArchive() {
+		args = append(args, "--format="+params.Format.Value.Key)
+	}
+	if params.Format == FormatDirectory && params.Jobs > 1 {
+		args = append(args, fmt.Sprintf("--jobs=%d", params.Jobs))
+	}
+	for _, pattern := range params.Schemas {
+		args = append(args, "--schema="+pattern)
+	}
+	for _, pattern := range params.ExcludeSchemas {
+		args = append(args, "--exclude-schema="+pattern)
+	}
+	for _, pattern := range params.Tables {
+		args = append(args, "--table="+pattern)
+	}
+	for _, pattern := range params.ExcludeTables {
+		args = append(args, "--exclude-table="+pattern)
+	}
+	for _, pattern := range params.ExcludeTableData {
+		args = append(args, "--exclude-table-data="+pattern)
+	}
+	args = append(args, params.Options.args()...)
+
+	return args
+}
+
+// Dump runs the pg_dump command with the given parameters. It returns the
+// dump as an io.Reader.
+//
+// Plain and custom dumps are streamed as generated by pg_dump. Directory
+// archives are first written to a temp dir and then streamed as a tar file.
+//
+// pg_dump is killed if the context is done before it finishes, the reader
+// then fails with the error of the context.
+func (Client) Dump(
+	ctx context.Context, version PGVersion, connString string,
+	params ...DumpParams,
+) io.Reader {
+	pickedParams := DumpParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
 
-	errorBuffer := &bytes.Buffer{}
 	reader, writer := io.Pipe()
-	cmd := exec.Command(version.Value.PGDump, args...)
+
+	if len(pickedParams.Options.Extensions) > 0 && version.major() < 14 {
+		writer.CloseWithError(fmt.Errorf(
+			"extension filters require pg_dump 14 or newer, got v%s",
+			version.Value.Version,
+		))
+		return reader
+	}
+
+	if pickedParams.Format == FormatDirectory {
+		go func() {
+			defer writer.Close()
+			err := dumpDirectoryToTar(ctx, writer, version, connString, pickedParams)
+			if err != nil {
+				writer.CloseWithError(err)
+			}
+		}()
+		return reader
+	}
+
+	connString, env := hidePassword(connString)
+	errorBuffer := &bytes.Buffer{}
+	cmd := commandContext(
+		ctx, version.Value.PGDump, dumpArgs(connString, pickedParams)...,
+	)
+	cmd.Env = env
 	cmd.Stdout = writer
 	cmd.Stderr = errorBuffer
 
 	go func() {
 		defer writer.Close()
 		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				writer.CloseWithError(ctx.Err())
+				return
+			}
 			writer.CloseWithError(fmt.Errorf(
 				"error running pg_dump v%s: %s",
 				version.Value.Version, errorBuffer.String(),
@@ -178,93 +308,431 @@ func (Client) Dump(
 	return reader
 }
 
-// DumpZip runs the pg_dump command with the given parameters and returns the
-// ZIP-compressed SQL dump as an io.Reader.
-func (c *Client) DumpZip(
-	version PGVersion, connString string, params ...DumpParams,
-) io.Reader {
-	dumpReader := c.Dump(version, connString, params...)
-	reader, writer := io.Pipe()
+// commandContext returns a command that is killed when the context is done.
+// Child processes that keep its output open, like parallel pg_dump workers or
+// the processes started by a hook, are not waited for more than a few
+// seconds after it is killed.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = 5 * time.Second
+	return cmd
+}
 
-	go func() {
-		defer writer.Close()
+// writeZipEntry creates a new file in the ZIP writer and copies the content
+// of the reader into it.
+func writeZipEntry(
+	zipWriter *zip.Writer, name string, method uint16, reader io.Reader,
+) error {
+	fileWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating zip file: %w", err)
+	}
+
+	if _, err := io.Copy(fileWriter, reader); err != nil {
+		return fmt.Errorf("error writing to zip file: %w", err)
+	}
+
+	return nil
+}
+
+// dumpDirectoryToTar runs pg_dump using the directory format into a temp dir
+// and then writes all the generated files to w as a tar file.
+func dumpDirectoryToTar(
+	ctx context.Context, w io.Writer, version PGVersion, connString string,
+	params DumpParams,
+) error {
+	workDir, err := os.MkdirTemp("", "pbw-dump-*")
+	if err != nil {
+		return fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	dumpDir := strutil.CreatePath(true, workDir, FormatDirectory.Value.ZipEntry)
+
+	connString, env := hidePassword(connString)
+	args := append(dumpArgs(connString, params), "--file="+dumpDir)
+	cmd := commandContext(ctx, version.Value.PGDump, args...)
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"error running pg_dump v%s: %s",
+			version.Value.Version, output,
+		)
+	}
+
+	return writeTar(w, dumpDir)
+}
+
+// RestoreParams contains the parameters for the pg_restore command.
+//
+// They are only used to restore custom and directory archives because pg_dump
+// ignores them when creating archives. Plain SQL dumps already include them.
+type RestoreParams struct {
+	// Clean (--clean): Drop database objects before recreating them.
+	Clean bool
+
+	// IfExists (--if-exists): Use DROP ... IF EXISTS commands to drop objects
+	// in --clean mode.
+	IfExists bool
+
+	// Create (--create): Create the database before restoring into it.
+	Create bool
+
+	// NoComments (--no-comments): Do not restore comments.
+	NoComments bool
+
+	// NoOwner (--no-owner): Do not set the ownership of the objects, they are
+	// owned by the role of the connection.
+	NoOwner bool
+
+	// NoPrivileges (--no-privileges): Do not restore access privileges.
+	NoPrivileges bool
 
-		zipWriter := zip.NewWriter(writer)
-		defer zipWriter.Close()
+	// Jobs (--jobs): Run the most time-consuming steps of the restore
+	// concurrently using this number of workers. Only used when greater than 1.
+	Jobs int
 
-		fileWriter, err := zipWriter.Create("dump.sql")
+	// ExitOnError (--exit-on-error): Stop at the first failed statement
+	// instead of reporting the errors at the end. Plain dumps are restored
+	// with psql -v ON_ERROR_STOP=1 --single-transaction, so nothing is
+	// applied if a statement fails.
+	ExitOnError bool
+
+	// DataOnly (--data-only): Restore only the data, not the schema.
+	DataOnly bool
+
+	// SchemaOnly (--schema-only): Restore only the schema, not the data.
+	SchemaOnly bool
+
+	// UseList (--use-list): Restore only these entries of the archive, in
+	// this order. Use ListArchive and FilterTOC to build it. All the entries
+	// are restored when empty.
+	UseList []TOCEntry
+}
+
+// restoreArgs returns the pg_restore arguments for the given parameters.
+//
+// The returned function removes the temp files needed by the arguments and
+// must be called after running pg_restore.
+func restoreArgs(
+	connString string, dumpFormat DumpFormat, params RestoreParams,
+) ([]string, func(), error) {
+	cleanup := func() {}
+
+	args := []string{"--dbname=" + connString}
+	if params.Clean {
+		args = append(args, "--clean")
+	}
+	if params.IfExists {
+		args = append(args, "--if-exists")
+	}
+	if params.Create {
+		args = append(args, "--create")
+	}
+	if params.NoComments {
+		args = append(args, "--no-comments")
+	}
+	if params.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if params.NoPrivileges {
+		args = append(args, "--no-privileges")
+	}
+	if params.ExitOnError {
+		args = append(args, "--exit-on-error")
+	}
+	if params.DataOnly {
+		args = append(args, "--data-only")
+	}
+	if params.SchemaOnly {
+		args = append(args, "--schema-only")
+	}
+	// Parallel restores are only possible reading a directory archive
+	if params.Jobs > 1 && dumpFormat == FormatDirectory {
+		args = append(args, fmt.Sprintf("--jobs=%d", params.Jobs))
+	}
+
+	if len(params.UseList) > 0 {
+		listFile, err := os.CreateTemp("", "pbw-list-*")
 		if err != nil {
-			writer.CloseWithError(fmt.Errorf("error creating zip file: %w", err))
-			return
+			return nil, cleanup, fmt.Errorf("error creating list file: %w", err)
 		}
-
-		if _, err := io.Copy(fileWriter, dumpReader); err != nil {
-			writer.CloseWithError(fmt.Errorf("error writing to zip file: %w", err))
-			return
+		cleanup = func() { os.Remove(listFile.Name()) }
+
+		_, err = io.WriteString(listFile, formatTOC(params.UseList))
+		closeErr := listFile.Close()
+		if err != nil || closeErr != nil {
+			return nil, cleanup, fmt.Errorf(
+				"error writing list file: %w", errors.Join(err, closeErr),
+			)
 		}
-	}()
 
-	return reader
+		args = append(args, "--use-list="+listFile.Name())
+	}
+
+	return append(args, "--format="+dumpFormat.Value.Key), cleanup, nil
 }
 
-// RestoreZip downloads or copies the ZIP from the given url or path, unzips it,
-// and runs the psql command to restore the database.
+// Restore restores the artifact read from r using psql or pg_restore,
+// decompressing it on the fly with the codec that was used to create it.
 //
-// The ZIP file must contain a dump.sql file with the SQL dump to restore.
+// Plain dumps and custom archives are streamed straight into the stdin of
+// psql or pg_restore without temp files. Directory archives are extracted
+// into a temp dir first because pg_restore can't read them from stdin.
+//
+// Legacy ZIP artifacts (CompressionZip) can't be streamed, use RestoreZip.
 //
 //   - version: PostgreSQL version to use for the restore
 //   - connString: connection string to the database
-//   - isLocal: whether the ZIP file is local or a URL
-//   - zipURLOrPath: URL or path to the ZIP file
+//   - r: content of the artifact, usually streamed from the storage backend
+//   - dumpFormat: format of the dump inside the artifact
+//   - comp: compression of the artifact
+//   - params: parameters used only for custom and directory archives
+//
+// psql or pg_restore is killed if the context is done before it finishes.
+func (Client) Restore(
+	ctx context.Context, version PGVersion, connString string, r io.Reader,
+	dumpFormat DumpFormat, comp Compression, params ...RestoreParams,
+) error {
+	pickedParams := RestoreParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
+
+	return handleDump(r, dumpFormat, comp, restoreHandler(
+		ctx, version, connString, pickedParams,
+	))
+}
+
+// RestoreZip restores a legacy ZIP artifact, created before the compression
+// codec could be selected, reading the dump found inside the ZIP from r.
+//
+// Plain dumps and custom archives are streamed from the ZIP entry without
+// temp files, directory archives are extracted into a temp dir first.
+//
+//   - version: PostgreSQL version to use for the restore
+//   - connString: connection string to the database
+//   - r: content of the ZIP file, usually read from the storage backend
+//   - size: size of the ZIP file, in bytes
+//   - params: parameters used only for custom and directory archives
+//
+// psql or pg_restore is killed if the context is done before it finishes.
 func (Client) RestoreZip(
-	version PGVersion, connString string, isLocal bool, zipURLOrPath string,
+	ctx context.Context, version PGVersion, connString string, r io.ReaderAt,
+	size int64, params ...RestoreParams,
 ) error {
-	workDir, err := os.MkdirTemp("", "pbw-restore-*")
+	pickedParams := RestoreParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
+
+	return handleZipDump(r, size, restoreHandler(
+		ctx, version, connString, pickedParams,
+	))
+}
+
+// dumpHandler runs a command on a dump, streamed for plain dumps and custom
+// archives or extracted into a directory for directory archives.
+type dumpHandler struct {
+	stream    func(r io.Reader, dumpFormat DumpFormat) error
+	directory func(dir string) error
+}
+
+// handleDump decompresses the artifact read from r and passes the dump to
+// the handler.
+func handleDump(
+	r io.Reader, dumpFormat DumpFormat, comp Compression, handler dumpHandler,
+) error {
+	if comp == CompressionZip {
+		return fmt.Errorf("ZIP backup files can't be streamed")
+	}
+
+	dumpReader, err := decompress(r, comp)
 	if err != nil {
-		return fmt.Errorf("error creating temp dir: %w", err)
+		return fmt.Errorf("error reading backup file: %w", err)
 	}
-	defer os.RemoveAll(workDir)
-	zipPath := strutil.CreatePath(true, workDir, "dump.zip")
-	dumpPath := strutil.CreatePath(true, workDir, "dump.sql")
+	defer dumpReader.Close()
 
-	if isLocal {
-		cmd := exec.Command("cp", zipURLOrPath, zipPath)
-		output, err := cmd.CombinedOutput()
+	if dumpFormat == FormatDirectory {
+		workDir, err := os.MkdirTemp("", "pbw-restore-*")
 		if err != nil {
-			return fmt.Errorf("error copying ZIP file to temp dir: %s", output)
+			return fmt.Errorf("error creating temp dir: %w", err)
+		}
+		defer os.RemoveAll(workDir)
+
+		if err := extractTar(dumpReader, workDir); err != nil {
+			return fmt.Errorf("error reading backup file: %w", err)
 		}
+
+		return handler.directory(workDir)
+	}
+
+	return handler.stream(dumpReader, dumpFormat)
+}
+
+// handleZipDump finds the dump inside the legacy ZIP artifact read from r and
+// passes it to the handler.
+func handleZipDump(r io.ReaderAt, size int64, handler dumpHandler) error {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("error reading ZIP file: %w", err)
 	}
 
-	if !isLocal {
-		cmd := exec.Command("wget", "--no-verbose", "-O", zipPath, zipURLOrPath)
-		output, err := cmd.CombinedOutput()
+	dumpFormat, err := detectZipDumpFormat(zipReader)
+	if err != nil {
+		return err
+	}
+
+	if dumpFormat == FormatDirectory {
+		workDir, err := os.MkdirTemp("", "pbw-restore-*")
+		if err != nil {
+			return fmt.Errorf("error creating temp dir: %w", err)
+		}
+		defer os.RemoveAll(workDir)
+
+		err = extractZipDir(zipReader, FormatDirectory.Value.ZipEntry, workDir)
 		if err != nil {
-			return fmt.Errorf("error downloading ZIP file: %s", output)
+			return err
 		}
+
+		return handler.directory(workDir)
 	}
 
-	if _, err := os.Stat(zipPath); os.IsNotExist(err) {
-		return fmt.Errorf("zip file not found: %s", zipPath)
+	entry, err := zipReader.Open(dumpFormat.Value.ZipEntry)
+	if err != nil {
+		return fmt.Errorf("error reading ZIP file: %w", err)
+	}
+	defer entry.Close()
+
+	return handler.stream(entry, dumpFormat)
+}
+
+// restoreHandler returns the handler that restores a dump using psql or
+// pg_restore.
+func restoreHandler(
+	ctx context.Context, version PGVersion, connString string,
+	params RestoreParams,
+) dumpHandler {
+	return dumpHandler{
+		stream: func(r io.Reader, dumpFormat DumpFormat) error {
+			return restoreStream(ctx, version, connString, r, dumpFormat, params)
+		},
+		directory: func(dir string) error {
+			return restoreDirectory(ctx, version, connString, dir, params)
+		},
 	}
+}
+
+// restoreStream pipes a plain dump into psql or a custom archive into
+// pg_restore.
+//
+// Errors reading the dump are reported instead of the output of the command
+// because they are the cause of the command failing.
+func restoreStream(
+	ctx context.Context, version PGVersion, connString string, r io.Reader,
+	dumpFormat DumpFormat, params RestoreParams,
+) error {
+	if !dumpFormat.IsArchive() && isSelective(params) {
+		return fmt.Errorf(
+			"selective restores require a custom or directory archive",
+		)
+	}
+
+	connString, env := hidePassword(connString)
+	name := "psql"
+	psqlArgs := []string{connString}
+	if params.ExitOnError {
+		psqlArgs = append(
+			psqlArgs, "-v", "ON_ERROR_STOP=1", "--single-transaction",
+		)
+	}
+	cmd := commandContext(
+		ctx, version.Value.PSQL, append(psqlArgs, "-f", "-")...,
+	)
+	if dumpFormat.IsArchive() {
+		args, cleanup, err := restoreArgs(connString, dumpFormat, params)
+		defer cleanup()
+		if err != nil {
+			return err
+		}
+
+		name = "pg_restore"
+		cmd = commandContext(ctx, version.Value.PGRestore, args...)
+	}
+
+	input := &readErrorTracker{r: r}
+	cmd.Env = env
+	cmd.Stdin = input
 
-	cmd := exec.Command("unzip", "-o", zipPath, "dump.sql", "-d", workDir)
 	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if input.err != nil {
+		return fmt.Errorf("error reading backup file: %w", input.err)
+	}
 	if err != nil {
-		return fmt.Errorf("error unzipping ZIP file: %s", output)
+		return fmt.Errorf(
+			"error running %s v%s command: %s",
+			name, version.Value.Version, output,
+		)
 	}
 
-	if _, err := os.Stat(dumpPath); os.IsNotExist(err) {
-		return fmt.Errorf("dump.sql file not found in ZIP file: %s", zipPath)
+	return nil
+}
+
+// restoreDirectory runs pg_restore for a directory archive extracted in dir.
+func restoreDirectory(
+	ctx context.Context, version PGVersion, connString string, dir string,
+	params RestoreParams,
+) error {
+	connString, env := hidePassword(connString)
+	args, cleanup, err := restoreArgs(connString, FormatDirectory, params)
+	defer cleanup()
+	if err != nil {
+		return err
 	}
 
-	cmd = exec.Command(version.Value.PSQL, connString, "-f", dumpPath)
-	output, err = cmd.CombinedOutput()
+	cmd := commandContext(ctx, version.Value.PGRestore, append(args, dir)...)
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
 		return fmt.Errorf(
-			"error running psql v%s command: %s",
+			"error running pg_restore v%s command: %s",
 			version.Value.Version, output,
 		)
 	}
 
 	return nil
 }
+
+// isSelective returns true if the parameters restore only part of the
+// archive, which is only possible with pg_restore.
+func isSelective(params RestoreParams) bool {
+	return params.DataOnly || params.SchemaOnly || len(params.UseList) > 0
+}
+
+// readErrorTracker records the first error, other than io.EOF, returned by
+// the wrapped reader.
+type readErrorTracker struct {
+	r   io.Reader
+	err error
+}
+
+func (t *readErrorTracker) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil && err != io.EOF && t.err == nil {
+		t.err = err
+	}
+	return n, err
+}
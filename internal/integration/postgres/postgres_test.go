@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTestPSQL prints a notice to stderr like a psqlrc file would, the
+// version is only printed when psqlrc files are skipped with -X.
+const fakeTestPSQL = `#!/bin/sh
+echo "NOTICE:  loaded psqlrc" >&2
+case "$*" in
+*"-X"*) echo "170002" ;;
+*) echo "Timing is on." && echo "170002" ;;
+esac
+`
+
+func TestClientTest(t *testing.T) {
+	binDir := t.TempDir()
+	createFakeVersion(t, binDir)
+	require.NoError(t, os.WriteFile(
+		filepath.Join(binDir, "psql"), []byte(fakeTestPSQL), 0o755,
+	))
+	version, err := NewVersion("17", binDir)
+	require.NoError(t, err)
+
+	client, err := New([]PGVersion{version})
+	require.NoError(t, err)
+
+	detected, err := client.Test(context.Background(), "postgresql://localhost/app")
+	assert.NoError(t, err)
+	assert.Equal(t, "17", detected.Value.Version)
+}
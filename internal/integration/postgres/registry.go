@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+// MinSupportedVersion is the oldest PostgreSQL major version that can be
+// registered, older versions are out of the PostgreSQL Version Policy.
+const MinSupportedVersion = 13
+
+// NewVersion returns the PGVersion for the given major version using the
+// client binaries located in binDir.
+//
+// It returns an error if the major version is invalid or unsupported, or if
+// the required binaries (pg_dump, pg_restore and psql) are missing.
+func NewVersion(major string, binDir string) (PGVersion, error) {
+	n, err := strconv.Atoi(major)
+	if err != nil || n < MinSupportedVersion {
+		return PGVersion{}, fmt.Errorf(
+			"invalid or unsupported PostgreSQL version %q, minimum is %d",
+			major, MinSupportedVersion,
+		)
+	}
+
+	v := PGVersion{version{
+		Version:      strconv.Itoa(n),
+		PGDump:       filepath.Join(binDir, "pg_dump"),
+		PSQL:         filepath.Join(binDir, "psql"),
+		PGRestore:    filepath.Join(binDir, "pg_restore"),
+		PGDumpAll:    filepath.Join(binDir, "pg_dumpall"),
+		PGBaseBackup: filepath.Join(binDir, "pg_basebackup"),
+		PGReceiveWAL: filepath.Join(binDir, "pg_receivewal"),
+	}}
+
+	for _, bin := range []string{v.Value.PGDump, v.Value.PGRestore, v.Value.PSQL} {
+		if !isExecutable(bin) {
+			return PGVersion{}, fmt.Errorf(
+				"PostgreSQL %s binary not found or not executable: %s",
+				v.Value.Version, bin,
+			)
+		}
+	}
+
+	return v, nil
+}
+
+// DiscoverVersionsParams contains the parameters for DiscoverVersions.
+type DiscoverVersionsParams struct {
+	// BinRoots are directories scanned for installed client binaries with the
+	// <root>/<major>/bin layout used by Debian and Ubuntu packages, for example
+	// /usr/lib/postgresql/17/bin. If a major version is found in several
+	// roots, the first one is used.
+	BinRoots []string
+
+	// BinDirs declares the binaries directory of a major version explicitly,
+	// for example {"18": "/opt/pg18/bin"}. It takes precedence over the
+	// versions found in BinRoots.
+	BinDirs map[string]string
+}
+
+// DiscoverVersions returns the PostgreSQL versions found in the system,
+// sorted from oldest to newest.
+//
+// Directories in BinRoots that are not a supported major version or do not
+// contain the required binaries are skipped, while invalid BinDirs entries
+// return an error because they are declared explicitly.
+func DiscoverVersions(params DiscoverVersionsParams) ([]PGVersion, error) {
+	found := map[string]PGVersion{}
+
+	for _, root := range params.BinRoots {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("error reading %s: %w", root, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			binDir := filepath.Join(root, entry.Name(), "bin")
+			v, err := NewVersion(entry.Name(), binDir)
+			if err != nil {
+				continue
+			}
+			if _, ok := found[v.Value.Version]; !ok {
+				found[v.Value.Version] = v
+			}
+		}
+	}
+
+	declared := map[string]string{}
+	for major, binDir := range params.BinDirs {
+		v, err := NewVersion(major, binDir)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := declared[v.Value.Version]; ok {
+			return nil, fmt.Errorf(
+				"PostgreSQL %s binaries declared twice: %s and %s",
+				v.Value.Version, other, binDir,
+			)
+		}
+		declared[v.Value.Version] = binDir
+		found[v.Value.Version] = v
+	}
+
+	versions := make([]PGVersion, 0, len(found))
+	for _, v := range found {
+		versions = append(versions, v)
+	}
+	slices.SortFunc(versions, func(a, b PGVersion) int {
+		return a.major() - b.major()
+	})
+
+	return versions, nil
+}
+
+// isExecutable returns true if the path is a regular file with any of the
+// execute permission bits set.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createFakeBinaries creates executable files with the given names in dir.
+func createFakeBinaries(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755))
+	}
+}
+
+// createFakeVersion creates the required client binaries of a version in
+// dir.
+func createFakeVersion(t *testing.T, dir string) {
+	createFakeBinaries(t, dir, "pg_dump", "pg_restore", "psql")
+}
+
+func TestNewVersion(t *testing.T) {
+	binDir := t.TempDir()
+	createFakeVersion(t, binDir)
+
+	incompleteDir := t.TempDir()
+	createFakeBinaries(t, incompleteDir, "pg_dump", "psql")
+
+	notExecutableDir := t.TempDir()
+	createFakeBinaries(t, notExecutableDir, "pg_dump", "psql")
+	require.NoError(t, os.WriteFile(
+		filepath.Join(notExecutableDir, "pg_restore"), []byte("#!/bin/sh\n"), 0o644,
+	))
+
+	tests := []struct {
+		name     string
+		major    string
+		binDir   string
+		expected string
+		wantErr  string
+	}{
+		{name: "valid", major: "17", binDir: binDir, expected: "17"},
+		{name: "leading zero", major: "017", binDir: binDir, expected: "17"},
+		{name: "minimum version", major: "13", binDir: binDir, expected: "13"},
+		{name: "unsupported version", major: "12", binDir: binDir, wantErr: "minimum is 13"},
+		{name: "invalid version", major: "latest", binDir: binDir, wantErr: "invalid"},
+		{name: "missing pg_restore", major: "17", binDir: incompleteDir, wantErr: "pg_restore"},
+		{name: "not executable", major: "17", binDir: notExecutableDir, wantErr: "pg_restore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVersion(tt.major, tt.binDir)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, v.Value.Version)
+			assert.Equal(t, filepath.Join(tt.binDir, "pg_restore"), v.Value.PGRestore)
+			assert.Equal(t, filepath.Join(tt.binDir, "pg_receivewal"), v.Value.PGReceiveWAL)
+		})
+	}
+}
+
+func TestDiscoverVersions(t *testing.T) {
+	versionsOf := func(versions []PGVersion) map[string]string {
+		result := map[string]string{}
+		for _, v := range versions {
+			result[v.Value.Version] = filepath.Dir(v.Value.PSQL)
+		}
+		return result
+	}
+
+	t.Run("scans the roots sorted from oldest to newest", func(t *testing.T) {
+		root := t.TempDir()
+		for _, major := range []string{"9", "18", "13", "16"} {
+			createFakeVersion(t, filepath.Join(root, major, "bin"))
+		}
+
+		versions, err := DiscoverVersions(DiscoverVersionsParams{
+			BinRoots: []string{root},
+		})
+		assert.NoError(t, err)
+
+		majors := []string{}
+		for _, v := range versions {
+			majors = append(majors, v.Value.Version)
+		}
+		assert.Equal(t, []string{"13", "16", "18"}, majors)
+	})
+
+	t.Run("skips directories without the required binaries", func(t *testing.T) {
+		root := t.TempDir()
+		createFakeVersion(t, filepath.Join(root, "17", "bin"))
+		createFakeBinaries(t, filepath.Join(root, "16", "bin"), "pg_dump", "psql")
+		createFakeBinaries(t, filepath.Join(root, "latest", "bin"), "pg_dump")
+		createFakeBinaries(t, root, "README")
+
+		versions, err := DiscoverVersions(DiscoverVersionsParams{
+			BinRoots: []string{root},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"17": filepath.Join(root, "17", "bin"),
+		}, versionsOf(versions))
+	})
+
+	t.Run("skips missing roots", func(t *testing.T) {
+		root := t.TempDir()
+		createFakeVersion(t, filepath.Join(root, "17", "bin"))
+
+		versions, err := DiscoverVersions(DiscoverVersionsParams{
+			BinRoots: []string{filepath.Join(root, "missing"), root},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, versions, 1)
+	})
+
+	t.Run("uses the first root of duplicate versions", func(t *testing.T) {
+		first := t.TempDir()
+		second := t.TempDir()
+		createFakeVersion(t, filepath.Join(first, "17", "bin"))
+		createFakeVersion(t, filepath.Join(second, "17", "bin"))
+		createFakeVersion(t, filepath.Join(second, "16", "bin"))
+
+		versions, err := DiscoverVersions(DiscoverVersionsParams{
+			BinRoots: []string{first, second},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"16": filepath.Join(second, "16", "bin"),
+			"17": filepath.Join(first, "17", "bin"),
+		}, versionsOf(versions))
+	})
+
+	t.Run("uses the first directory of versions with leading zeros", func(t *testing.T) {
+		root := t.TempDir()
+		createFakeVersion(t, filepath.Join(root, "017", "bin"))
+		createFakeVersion(t, filepath.Join(root, "17", "bin"))
+
+		versions, err := DiscoverVersions(DiscoverVersionsParams{
+			BinRoots: []string{root},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"17": filepath.Join(root, "017", "bin"),
+		}, versionsOf(versions))
+	})
+
+	t.Run("declared directories take precedence over the roots", func(t *testing.T) {
+		root := t.TempDir()
+		declared := t.TempDir()
+		createFakeVersion(t, filepath.Join(root, "17", "bin"))
+		createFakeVersion(t, filepath.Join(root, "16", "bin"))
+		createFakeVersion(t, declared)
+
+		versions, err := DiscoverVersions(DiscoverVersionsParams{
+			BinRoots: []string{root},
+			BinDirs:  map[string]string{"17": declared},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"16": filepath.Join(root, "16", "bin"),
+			"17": declared,
+		}, versionsOf(versions))
+	})
+
+	t.Run("declared directory without pg_restore", func(t *testing.T) {
+		declared := t.TempDir()
+		createFakeBinaries(t, declared, "pg_dump", "psql")
+
+		_, err := DiscoverVersions(DiscoverVersionsParams{
+			BinDirs: map[string]string{"18": declared},
+		})
+		assert.ErrorContains(t, err, "pg_restore")
+	})
+
+	t.Run("declared unsupported version", func(t *testing.T) {
+		declared := t.TempDir()
+		createFakeVersion(t, declared)
+
+		_, err := DiscoverVersions(DiscoverVersionsParams{
+			BinDirs: map[string]string{"12": declared},
+		})
+		assert.ErrorContains(t, err, "minimum is 13")
+	})
+
+	t.Run("version declared twice", func(t *testing.T) {
+		first := t.TempDir()
+		second := t.TempDir()
+		createFakeVersion(t, first)
+		createFakeVersion(t, second)
+
+		_, err := DiscoverVersions(DiscoverVersionsParams{
+			BinDirs: map[string]string{"17": first, "017": second},
+		})
+		assert.ErrorContains(t, err, "declared twice")
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		versions, err := DiscoverVersions(DiscoverVersionsParams{
+			BinRoots: []string{t.TempDir()},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, versions)
+	})
+}
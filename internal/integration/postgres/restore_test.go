@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePSQL behaves like psql running a script: failed statements are
+// reported and skipped, unless ON_ERROR_STOP is set, then psql exits with
+// code 3. The arguments are written to the args file next to it.
+const fakePSQL = `#!/bin/sh
+echo "$@" > "$(dirname "$0")/args"
+input=$(cat)
+case "$input" in
+*"SELECT broken"*)
+	echo 'ERROR:  column "broken" does not exist' >&2
+	case "$*" in
+	*"ON_ERROR_STOP=1"*) exit 3 ;;
+	esac
+	;;
+esac
+exit 0
+`
+
+func TestRestorePlainExitOnError(t *testing.T) {
+	binDir := t.TempDir()
+	createFakeVersion(t, binDir)
+	require.NoError(t, os.WriteFile(
+		filepath.Join(binDir, "psql"), []byte(fakePSQL), 0o755,
+	))
+	version, err := NewVersion("17", binDir)
+	require.NoError(t, err)
+
+	dump := "CREATE TABLE users (id int);\nSELECT broken FROM users;\n"
+
+	tests := []struct {
+		name     string
+		params   RestoreParams
+		wantArgs string
+		wantErr  string
+	}{
+		{
+			name:     "failed statements are skipped by default",
+			params:   RestoreParams{},
+			wantArgs: "postgresql://localhost/app -f -",
+		},
+		{
+			name:   "a failed statement fails the restore",
+			params: RestoreParams{ExitOnError: true},
+			wantArgs: "postgresql://localhost/app -v ON_ERROR_STOP=1 " +
+				"--single-transaction -f -",
+			wantErr: `column "broken" does not exist`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Client{}.Restore(
+				context.Background(), version, "postgresql://localhost/app",
+				strings.NewReader(dump), FormatPlain, CompressionNone, tt.params,
+			)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			args, err := os.ReadFile(filepath.Join(binDir, "args"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantArgs, strings.TrimSpace(string(args)))
+		})
+	}
+}
+
+func TestRestoreArgsExitOnError(t *testing.T) {
+	args, cleanup, err := restoreArgs(
+		"postgresql://localhost/app", FormatCustom,
+		RestoreParams{ExitOnError: true},
+	)
+	defer cleanup()
+	require.NoError(t, err)
+	assert.Contains(t, args, "--exit-on-error")
+
+	args, cleanup, err = restoreArgs(
+		"postgresql://localhost/app", FormatCustom, RestoreParams{},
+	)
+	defer cleanup()
+	require.NoError(t, err)
+	assert.NotContains(t, args, "--exit-on-error")
+}
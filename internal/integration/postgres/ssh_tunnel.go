@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHTunnelParams contains the settings of an SSH tunnel used to reach a
+// server through a bastion host.
+type SSHTunnelParams struct {
+	// Host is the address of the SSH server as host or host:port, the port
+	// defaults to 22. Empty disables the tunnel.
+	Host string
+
+	// User is the user to log in to the SSH server.
+	User string
+
+	// PrivateKey is the PEM encoded private key used to log in, without
+	// passphrase.
+	PrivateKey string
+
+	// HostKey is the public key of the SSH server in the authorized_keys
+	// format, as printed by ssh-keyscan. It is required, the connection is
+	// refused if the SSH server presents a different key.
+	HostKey string
+}
+
+// IsEnabled returns true if the server is reached through an SSH tunnel.
+func (p SSHTunnelParams) IsEnabled() bool {
+	return p.Host != ""
+}
+
+// SSHTunnel is a local port forward to a PostgreSQL server through an SSH
+// server.
+type SSHTunnel struct {
+	// ConnString is the connection string to use while the tunnel is open.
+	ConnString string
+
+	client   *ssh.Client
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// OpenSSHTunnel connects to the SSH server and listens on a random local
+// port that forwards every connection to the host and port of the connection
+// string. The returned tunnel must be closed when it is no longer used.
+//
+// The connection string of the tunnel keeps the original host, so TLS
+// certificates are still verified against it, and connects to the local port
+// using hostaddr.
+//
+// If the tunnel is not enabled, the returned tunnel uses the connection
+// string as is and closing it does nothing.
+func (Client) OpenSSHTunnel(
+	connString string, params SSHTunnelParams,
+) (*SSHTunnel, error) {
+	if !params.IsEnabled() {
+		return &SSHTunnel{ConnString: connString}, nil
+	}
+
+	target, err := connStringTarget(connString)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := sshClientConfig(params)
+	if err != nil {
+		return nil, err
+	}
+
+	address := params.Host
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "22")
+	}
+
+	client, err := ssh.Dial("tcp", address, config)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to SSH server %s: %w", address, err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error listening on a local port: %w", err)
+	}
+
+	localPort := fmt.Sprintf("%d", listener.Addr().(*net.TCPAddr).Port)
+	tunnelConnString, err := tunnelConnString(connString, localPort)
+	if err != nil {
+		listener.Close()
+		client.Close()
+		return nil, err
+	}
+
+	tunnel := &SSHTunnel{
+		ConnString: tunnelConnString,
+		client:     client,
+		listener:   listener,
+	}
+
+	tunnel.wg.Add(1)
+	go func() {
+		defer tunnel.wg.Done()
+		for {
+			local, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			tunnel.wg.Add(1)
+			go func() {
+				defer tunnel.wg.Done()
+				tunnel.forward(local, target)
+			}()
+		}
+	}()
+
+	return tunnel, nil
+}
+
+// Close stops listening on the local port and closes the SSH connection,
+// which ends the forwarded connections.
+func (t *SSHTunnel) Close() error {
+	if t.client == nil {
+		return nil
+	}
+
+	err := errors.Join(t.listener.Close(), t.client.Close())
+	t.wg.Wait()
+	return err
+}
+
+// forward copies the data between a local connection and the target address
+// reached through the SSH server.
+func (t *SSHTunnel) forward(local net.Conn, target string) {
+	defer local.Close()
+
+	remote, err := t.client.Dial("tcp", target)
+	if err != nil {
+		return
+	}
+	defer remote.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+
+	// When one side is closed the other one is closed by the defers
+	<-done
+}
+
+// sshClientConfig returns the configuration to log in to the SSH server.
+func sshClientConfig(params SSHTunnelParams) (*ssh.ClientConfig, error) {
+	if params.User == "" {
+		return nil, fmt.Errorf("SSH user is required")
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(params.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SSH private key: %w", err)
+	}
+
+	if strings.TrimSpace(params.HostKey) == "" {
+		return nil, fmt.Errorf(
+			"SSH host key is required, get it with ssh-keyscan %s", params.Host,
+		)
+	}
+	hostKey, err := parseSSHHostKey(params.HostKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssh.ClientConfig{
+		User:            params.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         15 * time.Second,
+	}, nil
+}
+
+// parseSSHHostKey parses a public key in the authorized_keys format, the
+// host names printed by ssh-keyscan before the key are ignored.
+func parseSSHHostKey(hostKey string) (ssh.PublicKey, error) {
+	hostKey = strings.TrimSpace(hostKey)
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+	if err == nil {
+		return key, nil
+	}
+
+	if _, rest, ok := strings.Cut(hostKey, " "); ok {
+		key, _, _, _, err = ssh.ParseAuthorizedKey([]byte(rest))
+		if err == nil {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("error parsing SSH host key: %w", err)
+}
+
+var (
+	connStringHostRegex     = connStringParamRegex("host")
+	connStringHostAddrRegex = connStringParamRegex("hostaddr")
+	connStringPortRegex     = connStringParamRegex("port")
+)
+
+// connStringTarget returns the host:port address of the server of the
+// connection string, as seen from the SSH server.
+func connStringTarget(connString string) (string, error) {
+	var host, hostAddr, port string
+
+	if isURIConnString(connString) {
+		u, err := url.Parse(connString)
+		if err != nil {
+			return "", fmt.Errorf("error parsing connection string: %w", err)
+		}
+		host = u.Hostname()
+		hostAddr = u.Query().Get("hostaddr")
+		port = u.Port()
+		if host == "" {
+			host = u.Query().Get("host")
+		}
+		if port == "" {
+			port = u.Query().Get("port")
+		}
+	} else {
+		host = connStringParam(connString, connStringHostRegex)
+		hostAddr = connStringParam(connString, connStringHostAddrRegex)
+		port = connStringParam(connString, connStringPortRegex)
+	}
+
+	if hostAddr != "" {
+		host = hostAddr
+	}
+	if host == "" || strings.HasPrefix(host, "/") {
+		return "", fmt.Errorf("SSH tunnels require a connection string with a host")
+	}
+	if strings.Contains(host, ",") || strings.Contains(port, ",") {
+		return "", fmt.Errorf("SSH tunnels don't support multiple hosts")
+	}
+	if port == "" {
+		port = "5432"
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// tunnelConnString returns the connection string that connects to the local
+// port of a tunnel, keeping the original host name.
+func tunnelConnString(connString string, localPort string) (string, error) {
+	if isURIConnString(connString) {
+		u, err := url.Parse(connString)
+		if err != nil {
+			return "", fmt.Errorf("error parsing connection string: %w", err)
+		}
+
+		query := u.Query()
+		query.Set("hostaddr", "127.0.0.1")
+		query.Del("port")
+		if u.Hostname() != "" {
+			u.Host = net.JoinHostPort(u.Hostname(), localPort)
+		} else {
+			query.Set("port", localPort)
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	connString = setConnStringParam(
+		connString, "hostaddr", connStringHostAddrRegex, "127.0.0.1",
+	)
+	return setConnStringParam(
+		connString, "port", connStringPortRegex, localPort,
+	), nil
+}
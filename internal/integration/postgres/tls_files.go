@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// TLSParams contains the PEM encoded certificates used to connect to a
+// server with TLS, they are written to temporary files because libpq only
+// reads them from files.
+type TLSParams struct {
+	// RootCert (sslrootcert) is the CA bundle used to verify the server
+	// certificate.
+	RootCert string
+
+	// ClientCert (sslcert) is the client certificate.
+	ClientCert string
+
+	// ClientKey (sslkey) is the private key of the client certificate.
+	ClientKey string
+}
+
+// IsEnabled returns true if any certificate is set.
+func (p TLSParams) IsEnabled() bool {
+	return p.RootCert != "" || p.ClientCert != "" || p.ClientKey != ""
+}
+
+var (
+	connStringSSLRootCertRegex = connStringParamRegex("sslrootcert")
+	connStringSSLCertRegex     = connStringParamRegex("sslcert")
+	connStringSSLKeyRegex      = connStringParamRegex("sslkey")
+)
+
+// writeTLSFiles writes the certificates to a new private temporary directory
+// and returns the connection string that references them and the directory,
+// which must be removed when the files are no longer used.
+//
+// If no certificate is set, the connection string is returned as is with an
+// empty directory.
+func writeTLSFiles(
+	connString string, params TLSParams,
+) (string, string, error) {
+	if !params.IsEnabled() {
+		return connString, "", nil
+	}
+
+	if (params.ClientCert == "") != (params.ClientKey == "") {
+		return "", "", fmt.Errorf(
+			"TLS client certificate and key must be provided together",
+		)
+	}
+
+	files := []struct {
+		name  string
+		key   string
+		regex *regexp.Regexp
+		value string
+	}{
+		{
+			name: "root.crt", key: "sslrootcert",
+			regex: connStringSSLRootCertRegex, value: params.RootCert,
+		},
+		{
+			name: "client.crt", key: "sslcert",
+			regex: connStringSSLCertRegex, value: params.ClientCert,
+		},
+		{
+			name: "client.key", key: "sslkey",
+			regex: connStringSSLKeyRegex, value: params.ClientKey,
+		},
+	}
+
+	for _, f := range files {
+		if f.value != "" {
+			if block, _ := pem.Decode([]byte(f.value)); block == nil {
+				return "", "", fmt.Errorf("%s is not a valid PEM file", f.key)
+			}
+		}
+	}
+
+	// MkdirTemp creates the directory readable only by the current user and
+	// libpq refuses client keys readable by others
+	dir, err := os.MkdirTemp("", "pbw-tls-*")
+	if err != nil {
+		return "", "", fmt.Errorf("error creating TLS files directory: %w", err)
+	}
+
+	for _, f := range files {
+		if f.value == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, []byte(f.value), 0o600); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", "", fmt.Errorf("error writing TLS file %s: %w", f.key, err)
+		}
+
+		connString, err = setConnStringFile(connString, f.key, f.regex, path)
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return "", "", err
+		}
+	}
+
+	return connString, dir, nil
+}
+
+// setConnStringFile sets a parameter of the connection string that
+// references a file, it supports both the URI and the key/value formats.
+func setConnStringFile(
+	connString string, key string, regex *regexp.Regexp, path string,
+) (string, error) {
+	if isURIConnString(connString) {
+		u, err := url.Parse(connString)
+		if err != nil {
+			return "", fmt.Errorf("error parsing connection string: %w", err)
+		}
+		query := u.Query()
+		query.Set(key, path)
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	return setConnStringParam(connString, key, regex, path), nil
+}
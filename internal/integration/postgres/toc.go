@@ -0,0 +1,357 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TOCEntry is an entry of the table of contents of a custom or directory
+// archive, as printed by pg_restore --list.
+type TOCEntry struct {
+	// ID is the dump ID of the entry, used by --use-list.
+	ID int
+	// Type is the kind of object, for example TABLE or TABLE DATA.
+	Type string
+	// Schema is the schema of the object, empty for objects without schema.
+	Schema string
+	// Name is the name of the object. For objects that belong to a table,
+	// like constraints and triggers, it is the table name followed by the
+	// object name.
+	Name string
+	// Owner is the role that owns the object, empty if it has no owner.
+	Owner string
+	// Line is the original line printed by pg_restore --list.
+	Line string
+}
+
+// IsData returns true if the entry restores data instead of definitions.
+func (e TOCEntry) IsData() bool {
+	switch e.Type {
+	case "TABLE DATA", "SEQUENCE SET", "BLOB", "BLOBS", "LARGE OBJECT",
+		"LARGE OBJECTS", "BLOB DATA", "MATERIALIZED VIEW DATA":
+		return true
+	default:
+		return false
+	}
+}
+
+// tocTypes are the multi-word object types printed by pg_restore --list,
+// sorted from the longest so prefixes are matched correctly.
+var tocTypes = []string{
+	"PUBLICATION TABLES IN SCHEMA",
+	"TEXT SEARCH CONFIGURATION",
+	"TEXT SEARCH DICTIONARY",
+	"MATERIALIZED VIEW DATA",
+	"TEXT SEARCH TEMPLATE",
+	"FOREIGN DATA WRAPPER",
+	"TEXT SEARCH PARSER",
+	"SUBSCRIPTION TABLE",
+	"DATABASE PROPERTIES",
+	"PROCEDURAL LANGUAGE",
+	"SEQUENCE OWNED BY",
+	"MATERIALIZED VIEW",
+	"PUBLICATION TABLE",
+	"CHECK CONSTRAINT",
+	"STATISTICS DATA",
+	"OPERATOR FAMILY",
+	"OPERATOR CLASS",
+	"SECURITY LABEL",
+	"ACCESS METHOD",
+	"EVENT TRIGGER",
+	"FOREIGN TABLE",
+	"LARGE OBJECTS",
+	"FK CONSTRAINT",
+	"SEQUENCE SET",
+	"USER MAPPING",
+	"LARGE OBJECT",
+	"ROW SECURITY",
+	"TABLE ATTACH",
+	"INDEX ATTACH",
+	"DEFAULT ACL",
+	"SHELL TYPE",
+	"TABLE DATA",
+	"BLOB DATA",
+}
+
+// parseTOC parses the output of pg_restore --list, skipping comments and
+// lines that are not entries.
+//
+// pg_restore prints the schema, name and owner separated by single spaces
+// without quoting them, and an empty owner as an empty string. The schema is
+// resolved with the schemas of the archive, so schemas and names can contain
+// spaces, and the owner is the last word of the line.
+func parseTOC(output string) []TOCEntry {
+	type tocLine struct {
+		id      int
+		typ     string
+		payload []string
+		line    string
+	}
+
+	lines := []tocLine{}
+	schemas := []string{"public"}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		idStr, rest, ok := strings.Cut(line, ";")
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil {
+			continue
+		}
+
+		// Skip the catalog table OID and the object OID
+		fields := strings.SplitN(strings.TrimLeft(rest, " "), " ", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		rest = fields[2]
+
+		entryType, _, _ := strings.Cut(rest, " ")
+		for _, t := range tocTypes {
+			if strings.HasPrefix(rest, t+" ") {
+				entryType = t
+				break
+			}
+		}
+
+		payload := strings.Split(strings.TrimPrefix(rest, entryType+" "), " ")
+		if entryType == "SCHEMA" && len(payload) >= 3 {
+			schemas = append(schemas, strings.Join(payload[1:len(payload)-1], " "))
+		}
+
+		lines = append(lines, tocLine{
+			id: id, typ: entryType, payload: payload, line: line,
+		})
+	}
+
+	// Match the longest schemas first, in case a schema name starts with
+	// another one followed by a space
+	slices.SortFunc(schemas, func(a, b string) int { return len(b) - len(a) })
+
+	entries := make([]TOCEntry, 0, len(lines))
+	for _, l := range lines {
+		entry := TOCEntry{ID: l.id, Type: l.typ, Line: l.line}
+		payload := l.payload
+
+		if len(payload) > 1 {
+			entry.Owner = payload[len(payload)-1]
+			payload = payload[:len(payload)-1]
+		}
+
+		schemaWords := 1
+		if payload[0] != "-" {
+			joined := strings.Join(payload, " ")
+			for _, schema := range schemas {
+				if strings.HasPrefix(joined, schema+" ") {
+					entry.Schema = schema
+					schemaWords = len(strings.Split(schema, " "))
+					break
+				}
+			}
+			if entry.Schema == "" {
+				entry.Schema = payload[0]
+			}
+		}
+
+		if len(payload) > schemaWords {
+			entry.Name = strings.Join(payload[schemaWords:], " ")
+		} else if entry.Schema == "" {
+			entry.Name = strings.Join(payload, " ")
+			if entry.Name == "-" {
+				entry.Name = ""
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// formatTOC returns the entries in the format accepted by
+// pg_restore --use-list.
+func formatTOC(entries []TOCEntry) string {
+	var sb strings.Builder
+	for _, entry := range entries {
+		sb.WriteString(entry.Line)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// tocTableTypes are the types of the entries that define a relation whose
+// dependent objects can be restored with it.
+var tocTableTypes = []string{
+	"TABLE", "FOREIGN TABLE", "VIEW", "MATERIALIZED VIEW",
+}
+
+// tocObjectPrefixes are the object types that prefix the name of comments,
+// ACLs and security labels, like TABLE users in COMMENT entries.
+var tocObjectPrefixes = []string{
+	"TABLE ", "FOREIGN TABLE ", "VIEW ", "MATERIALIZED VIEW ",
+}
+
+// FilterTOC returns the entries that belong to any of the given schemas or
+// tables, keeping their order.
+//
+// Tables are written as schema.table, or table to match it in the public
+// schema. An entry belongs to a table when its name is the table name, like
+// the table itself and its data, or starts with it, like its constraints and
+// triggers, and to the longest table name of its schema when several match.
+// The comments and ACLs of the schemas and tables are included too.
+//
+// Indexes are not linked to their table in the list, add them by name to the
+// tables or select the whole schema.
+func FilterTOC(entries []TOCEntry, schemas []string, tables []string) []TOCEntry {
+	type tableKey struct{ schema, name string }
+
+	// Resolve the tables against the objects of the archive, so names with
+	// dots are matched correctly
+	tableNames := map[string][]string{}
+	selected := map[tableKey]bool{}
+	for _, entry := range entries {
+		if entry.Schema == "" {
+			continue
+		}
+		if slices.Contains(tocTableTypes, entry.Type) {
+			tableNames[entry.Schema] = append(tableNames[entry.Schema], entry.Name)
+		}
+		for _, table := range tables {
+			if table == entry.Schema+"."+entry.Name ||
+				(entry.Schema == "public" && table == entry.Name) {
+				selected[tableKey{entry.Schema, entry.Name}] = true
+			}
+		}
+	}
+
+	filtered := []TOCEntry{}
+	for _, entry := range entries {
+		name := entry.Name
+		switch entry.Type {
+		case "COMMENT", "ACL", "SECURITY LABEL":
+			schema, ok := strings.CutPrefix(name, "SCHEMA ")
+			if ok && entry.Schema == "" && slices.Contains(schemas, schema) {
+				filtered = append(filtered, entry)
+				continue
+			}
+			for _, prefix := range tocObjectPrefixes {
+				if trimmed, ok := strings.CutPrefix(name, prefix); ok {
+					name = trimmed
+					break
+				}
+			}
+		case "SCHEMA":
+			if slices.Contains(schemas, name) {
+				filtered = append(filtered, entry)
+				continue
+			}
+		}
+
+		if entry.Schema != "" && slices.Contains(schemas, entry.Schema) {
+			filtered = append(filtered, entry)
+			continue
+		}
+
+		table := entryTable(name, tableNames[entry.Schema])
+		if selected[tableKey{entry.Schema, name}] ||
+			(table != "" && selected[tableKey{entry.Schema, table}]) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered
+}
+
+// entryTable returns the table of the given names that an entry name belongs
+// to, preferring the longest one, or empty if it belongs to none.
+func entryTable(name string, tables []string) string {
+	table := ""
+	for _, t := range tables {
+		if len(t) <= len(table) {
+			continue
+		}
+		if name == t || strings.HasPrefix(name, t+" ") {
+			table = t
+		}
+	}
+	return table
+}
+
+// ListArchive returns the table of contents of the custom or directory
+// archive read from r, decompressing it with the codec that was used to
+// create it.
+//
+// Legacy ZIP artifacts (CompressionZip) can't be streamed, use
+// ListArchiveZip.
+//
+// pg_restore is killed if the context is done before it finishes.
+func (Client) ListArchive(
+	ctx context.Context, version PGVersion, r io.Reader,
+	dumpFormat DumpFormat, comp Compression,
+) ([]TOCEntry, error) {
+	var entries []TOCEntry
+	err := handleDump(r, dumpFormat, comp, listHandler(ctx, version, &entries))
+	return entries, err
+}
+
+// ListArchiveZip returns the table of contents of the custom or directory
+// archive found inside the legacy ZIP artifact read from r.
+//
+// pg_restore is killed if the context is done before it finishes.
+func (Client) ListArchiveZip(
+	ctx context.Context, version PGVersion, r io.ReaderAt, size int64,
+) ([]TOCEntry, error) {
+	var entries []TOCEntry
+	err := handleZipDump(r, size, listHandler(ctx, version, &entries))
+	return entries, err
+}
+
+// listHandler returns the handler that stores the table of contents of an
+// archive into entries.
+func listHandler(
+	ctx context.Context, version PGVersion, entries *[]TOCEntry,
+) dumpHandler {
+	list := func(stdin io.Reader, args ...string) error {
+		errorBuffer := &bytes.Buffer{}
+		cmd := commandContext(
+			ctx, version.Value.PGRestore, append([]string{"--list"}, args...)...,
+		)
+		cmd.Stdin = stdin
+		cmd.Stderr = errorBuffer
+
+		output, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf(
+				"error running pg_restore v%s --list: %s",
+				version.Value.Version, errorBuffer.String(),
+			)
+		}
+
+		*entries = parseTOC(string(output))
+		return nil
+	}
+
+	return dumpHandler{
+		stream: func(r io.Reader, dumpFormat DumpFormat) error {
+			if !dumpFormat.IsArchive() {
+				return fmt.Errorf("plain dumps have no table of contents")
+			}
+			return list(r, "--format="+dumpFormat.Value.Key)
+		},
+		directory: func(dir string) error {
+			return list(nil, "--format="+FormatDirectory.Value.Key, dir)
+		},
+	}
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testTOC is the output of pg_restore --list of a custom archive, with names
+// and schemas that contain spaces and entries without owner.
+const testTOC = `;
+; Archive created at 2026-10-15 10:00:00 UTC
+;     dbname: shop
+;     TOC Entries: 31
+;     Compression: gzip
+;     Dump Version: 1.15-0
+;     Format: CUSTOM
+;     Integer: 4 bytes
+;     Offset: 8 bytes
+;     Dumped from database version: 16.4
+;     Dumped by pg_dump version: 16.4
+;
+;
+; Selected TOC Entries:
+;
+6; 2615 16389 SCHEMA - sales app
+7; 2615 16390 SCHEMA - my schema app
+3480; 0 0 ACL - SCHEMA sales app
+2; 3079 16391 EXTENSION - pgcrypto 
+3481; 0 0 COMMENT - EXTENSION pgcrypto 
+218; 1259 16430 TABLE public users app
+219; 1259 16436 SEQUENCE public users_id_seq app
+3482; 0 0 SEQUENCE OWNED BY public users_id_seq app
+220; 1259 16440 TABLE public order app
+221; 1259 16446 TABLE public order items app
+222; 1259 16450 TABLE sales orders app
+223; 1259 16455 TABLE sales orders_archive app
+224; 1259 16460 TABLE my schema audit log app
+3310; 2604 16437 DEFAULT public users id app
+3470; 0 16430 TABLE DATA public users app
+3471; 0 16440 TABLE DATA public order app
+3472; 0 16446 TABLE DATA public order items app
+3473; 0 16450 TABLE DATA sales orders app
+3474; 0 16460 TABLE DATA my schema audit log app
+3483; 0 0 SEQUENCE SET public users_id_seq app
+3312; 2606 16442 CONSTRAINT public order order_pkey app
+3313; 2606 16448 CONSTRAINT public order items order items_pkey app
+3314; 2606 16432 CONSTRAINT public users users_pkey app
+3316; 2606 16452 CONSTRAINT sales orders orders_pkey app
+3317; 2606 16454 CHECK CONSTRAINT sales orders orders_total_check app
+3318; 1259 16470 INDEX public users_email_idx app
+3320; 2620 16480 TRIGGER public users users_audit app
+3322; 2606 16490 FK CONSTRAINT sales orders orders_user_id_fkey app
+3484; 0 0 COMMENT public TABLE users app
+3485; 0 0 ACL public TABLE users app
+3486; 3466 16500 EVENT TRIGGER - log ddl app
+`
+
+func TestParseTOC(t *testing.T) {
+	entries := parseTOC(testTOC)
+	assert.Len(t, entries, 31)
+
+	tests := []struct {
+		id     int
+		typ    string
+		schema string
+		name   string
+		owner  string
+		isData bool
+	}{
+		{id: 6, typ: "SCHEMA", name: "sales", owner: "app"},
+		{id: 7, typ: "SCHEMA", name: "my schema", owner: "app"},
+		{id: 3480, typ: "ACL", name: "SCHEMA sales", owner: "app"},
+		{id: 2, typ: "EXTENSION", name: "pgcrypto"},
+		{id: 3481, typ: "COMMENT", name: "EXTENSION pgcrypto"},
+		{id: 218, typ: "TABLE", schema: "public", name: "users", owner: "app"},
+		{id: 3482, typ: "SEQUENCE OWNED BY", schema: "public", name: "users_id_seq", owner: "app"},
+		{id: 221, typ: "TABLE", schema: "public", name: "order items", owner: "app"},
+		{id: 224, typ: "TABLE", schema: "my schema", name: "audit log", owner: "app"},
+		{id: 3310, typ: "DEFAULT", schema: "public", name: "users id", owner: "app"},
+		{id: 3472, typ: "TABLE DATA", schema: "public", name: "order items", owner: "app", isData: true},
+		{id: 3474, typ: "TABLE DATA", schema: "my schema", name: "audit log", owner: "app", isData: true},
+		{id: 3483, typ: "SEQUENCE SET", schema: "public", name: "users_id_seq", owner: "app", isData: true},
+		{id: 3313, typ: "CONSTRAINT", schema: "public", name: "order items order items_pkey", owner: "app"},
+		{id: 3317, typ: "CHECK CONSTRAINT", schema: "sales", name: "orders orders_total_check", owner: "app"},
+		{id: 3318, typ: "INDEX", schema: "public", name: "users_email_idx", owner: "app"},
+		{id: 3320, typ: "TRIGGER", schema: "public", name: "users users_audit", owner: "app"},
+		{id: 3322, typ: "FK CONSTRAINT", schema: "sales", name: "orders orders_user_id_fkey", owner: "app"},
+		{id: 3484, typ: "COMMENT", schema: "public", name: "TABLE users", owner: "app"},
+		{id: 3486, typ: "EVENT TRIGGER", name: "log ddl", owner: "app"},
+	}
+
+	byID := map[int]TOCEntry{}
+	for _, entry := range entries {
+		byID[entry.ID] = entry
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ+" "+tt.name, func(t *testing.T) {
+			entry, ok := byID[tt.id]
+			assert.True(t, ok)
+			assert.Equal(t, tt.typ, entry.Type)
+			assert.Equal(t, tt.schema, entry.Schema)
+			assert.Equal(t, tt.name, entry.Name)
+			assert.Equal(t, tt.owner, entry.Owner)
+			assert.Equal(t, tt.isData, entry.IsData())
+		})
+	}
+}
+
+func TestParseTOCKeepsLines(t *testing.T) {
+	entries := parseTOC("3; 3079 16391 EXTENSION - pgcrypto \r\nnot an entry\n")
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "3; 3079 16391 EXTENSION - pgcrypto ", entries[0].Line)
+	assert.Equal(t, "3; 3079 16391 EXTENSION - pgcrypto \n", formatTOC(entries))
+}
+
+func TestFilterTOC(t *testing.T) {
+	entries := parseTOC(testTOC)
+
+	tests := []struct {
+		name     string
+		schemas  []string
+		tables   []string
+		expected []int
+	}{
+		{
+			name:     "nothing selected",
+			expected: []int{},
+		},
+		{
+			name:    "schema",
+			schemas: []string{"sales"},
+			expected: []int{
+				6, 3480, 222, 223, 3473, 3316, 3317, 3322,
+			},
+		},
+		{
+			name:     "schema with spaces",
+			schemas:  []string{"my schema"},
+			expected: []int{7, 224, 3474},
+		},
+		{
+			name:   "table with constraints, triggers, comments and ACLs",
+			tables: []string{"public.users"},
+			expected: []int{
+				218, 3310, 3470, 3314, 3320, 3484, 3485,
+			},
+		},
+		{
+			name:     "table doesn't match tables that start with its name",
+			tables:   []string{"public.order"},
+			expected: []int{220, 3471, 3312},
+		},
+		{
+			name:     "table with spaces",
+			tables:   []string{"public.order items"},
+			expected: []int{221, 3472, 3313},
+		},
+		{
+			name:     "table in a schema with spaces",
+			tables:   []string{"my schema.audit log"},
+			expected: []int{224, 3474},
+		},
+		{
+			name:     "table with foreign keys and check constraints",
+			tables:   []string{"sales.orders"},
+			expected: []int{222, 3473, 3316, 3317, 3322},
+		},
+		{
+			name:     "unqualified table matches the public schema",
+			tables:   []string{"users"},
+			expected: []int{218, 3310, 3470, 3314, 3320, 3484, 3485},
+		},
+		{
+			name:     "unqualified table doesn't match other schemas",
+			tables:   []string{"orders"},
+			expected: []int{},
+		},
+		{
+			name:     "index by name",
+			tables:   []string{"public.users_email_idx"},
+			expected: []int{3318},
+		},
+		{
+			name:     "unknown table",
+			tables:   []string{"public.missing"},
+			expected: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []int{}
+			for _, entry := range FilterTOC(entries, tt.schemas, tt.tables) {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
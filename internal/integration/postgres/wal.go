@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// walFileRegex matches the names of the completed files written by
+// pg_receivewal: WAL segments and timeline history files.
+var walFileRegex = regexp.MustCompile(`^([0-9A-F]{24}|[0-9A-F]{8}\.history)$`)
+
+// IsWALFile returns true if the given file name is a completed WAL segment
+// or timeline history file, partial segments return false.
+func IsWALFile(name string) bool {
+	return walFileRegex.MatchString(name)
+}
+
+// WALReceiver is a running pg_receivewal process.
+type WALReceiver struct {
+	cmd         *exec.Cmd
+	errorBuffer *bytes.Buffer
+	done        chan struct{}
+	err         error
+	cancel      context.CancelFunc
+}
+
+// StartWALReceiver starts a pg_receivewal process that streams the WAL of the
+// cluster into the given directory using a physical replication slot, so no
+// segment is lost between restarts. The slot is created if it doesn't exist.
+//
+// The process exits when the connection is lost or the context is done,
+// check Running and start a new receiver when needed.
+func (Client) StartWALReceiver(
+	ctx context.Context, version PGVersion, connString string, dir string, slot string,
+) (*WALReceiver, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("error creating WAL directory %s: %w", dir, err)
+	}
+
+	connString, env := hidePassword(connString)
+	cmd := commandContext(
+		ctx, version.Value.PGReceiveWAL,
+		"--dbname="+connString,
+		"--slot="+slot,
+		"--create-slot",
+		"--if-not-exists",
+	)
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error creating replication slot with pg_receivewal v%s: %s",
+			version.Value.Version, output,
+		)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	receiver := &WALReceiver{
+		errorBuffer: &bytes.Buffer{},
+		done:        make(chan struct{}),
+		cancel:      cancel,
+	}
+	receiver.cmd = commandContext(
+		ctx, version.Value.PGReceiveWAL,
+		"--dbname="+connString,
+		"--slot="+slot,
+		"--directory="+dir,
+		"--no-loop",
+		"--no-password",
+	)
+	receiver.cmd.Env = env
+	receiver.cmd.Stderr = receiver.errorBuffer
+
+	if err := receiver.cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf(
+			"error starting pg_receivewal v%s: %w", version.Value.Version, err,
+		)
+	}
+
+	go func() {
+		defer close(receiver.done)
+		if err := receiver.cmd.Wait(); err != nil {
+			receiver.err = fmt.Errorf(
+				"error running pg_receivewal v%s: %s",
+				version.Value.Version, receiver.errorBuffer.String(),
+			)
+		}
+	}()
+
+	return receiver, nil
+}
+
+// Running returns true if the pg_receivewal process is still running.
+func (r *WALReceiver) Running() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Err returns the error of the pg_receivewal process once it has exited.
+func (r *WALReceiver) Err() error {
+	if r.Running() {
+		return nil
+	}
+	return r.err
+}
+
+// Stop kills the pg_receivewal process and waits for it to exit.
+func (r *WALReceiver) Stop() {
+	r.cancel()
+	<-r.done
+}
+
+// DropReplicationSlot drops the replication slot, if it exists, using psql.
+// The slot must be dropped when WAL archiving stops, otherwise the server
+// keeps every WAL file until its disk is full.
+//
+// A slot stays active for a moment after its receiver is stopped, so the
+// drop is retried a few times.
+func (Client) DropReplicationSlot(
+	version PGVersion, connString string, slot string,
+) error {
+	connString, env := hidePassword(connString)
+
+	var output []byte
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Second)
+		}
+
+		cmd := exec.Command(
+			version.Value.PSQL, connString, "-X", "-v", "ON_ERROR_STOP=1",
+			"-v", "slot="+slot,
+		)
+		cmd.Env = env
+		cmd.Stdin = strings.NewReader(
+			"SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots " +
+				"WHERE slot_name = :'slot';\n",
+		)
+		output, err = cmd.CombinedOutput()
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"error dropping replication slot %s with psql v%s: %s",
+		slot, version.Value.Version, output,
+	)
+}
+
+// PITRRecoveryInstructions returns the steps to restore a point-in-time
+// recovery bundle, which contains a base backup generated by BaseBackupZip
+// and the WAL files needed to replay it until the target time.
+func PITRRecoveryInstructions(version PGVersion, targetTime time.Time) string {
+	return fmt.Sprintf(`Point-in-time recovery bundle for PostgreSQL %[1]s
+
+Target time: %[2]s
+
+This bundle contains a physical base backup (base-backup.zip) and the
+archived WAL files (wal directory) needed to replay the cluster until the
+target time. It must be restored on a server running PostgreSQL %[1]s.
+
+1. Stop the PostgreSQL server:
+     pg_ctl stop -D "$PGDATA"
+
+2. Move the current data directory away (or delete it):
+     mv "$PGDATA" "$PGDATA.old"
+
+3. Create an empty data directory owned by the postgres user:
+     mkdir -m 700 "$PGDATA"
+     chown postgres:postgres "$PGDATA"
+
+4. Extract the base backup into it:
+     unzip base-backup.zip %[3]s
+     tar -xf %[3]s -C "$PGDATA"
+
+5. Copy the wal directory of this bundle to a path readable by the postgres
+   user, for example /var/lib/postgresql/pitr-wal.
+
+6. Append the contents of recovery.conf (from this bundle) to
+   "$PGDATA/postgresql.auto.conf", fixing the restore_command path if you
+   used a different directory in the previous step, and create the recovery
+   signal file:
+     cat recovery.conf >> "$PGDATA/postgresql.auto.conf"
+     touch "$PGDATA/recovery.signal"
+
+7. Start the PostgreSQL server:
+     pg_ctl start -D "$PGDATA"
+
+The server replays the WAL until the target time and then promotes itself.
+Remove the recovery settings from postgresql.auto.conf once it is done.
+`, version.Value.Version, targetTime.UTC().Format(time.RFC3339), baseBackupZipEntry)
+}
+
+// PITRRecoveryConf returns the recovery settings that must be added to the
+// postgresql.auto.conf file to restore a point-in-time recovery bundle.
+func PITRRecoveryConf(targetTime time.Time) string {
+	lines := []string{
+		"restore_command = 'cp /var/lib/postgresql/pitr-wal/%f %p'",
+		fmt.Sprintf(
+			"recovery_target_time = '%s'",
+			targetTime.UTC().Format("2006-01-02 15:04:05.999999-07"),
+		),
+		"recovery_target_action = 'promote'",
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
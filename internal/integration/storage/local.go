@@ -16,7 +16,8 @@ const (
 // LocalUpload Creates a new file using the provided path and reader relative
 // to the local backups directory.
 //
-// Returns the size of the file created, in bytes.
+// Returns the size of the file created, in bytes. If the reader fails, for
+// example because the backup was cancelled, the partial file is removed.
 func (Client) LocalUpload(relativeFilePath string, fileReader io.Reader) (int64, error) {
 	fullPath := strutil.CreatePath(true, localBackupsDir, relativeFilePath)
 	dir := filepath.Dir(fullPath)
@@ -34,6 +35,7 @@ func (Client) LocalUpload(relativeFilePath string, fileReader io.Reader) (int64,
 
 	_, err = io.Copy(file, fileReader)
 	if err != nil {
+		_ = os.Remove(fullPath)
 		return 0, fmt.Errorf("failed to write file %s: %w", fullPath, err)
 	}
 
@@ -58,6 +60,51 @@ func (Client) LocalDelete(relativeFilePath string) error {
 	return nil
 }
 
+// LocalOpen Opens a file using the provided path relative to the local
+// backups directory.
+//
+// The caller must close the returned reader.
+func (Client) LocalOpen(relativeFilePath string) (io.ReadCloser, error) {
+	fullPath := strutil.CreatePath(true, localBackupsDir, relativeFilePath)
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", fullPath, err)
+	}
+
+	return file, nil
+}
+
+// LocalOpenAt Opens a file using the provided path relative to the local
+// backups directory so it can be read at any offset.
+//
+// The caller must close the returned reader.
+func (Client) LocalOpenAt(relativeFilePath string) (ReaderAt, error) {
+	fullPath := strutil.CreatePath(true, localBackupsDir, relativeFilePath)
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", fullPath, err)
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to get file info %s: %w", fullPath, err)
+	}
+
+	return &localReaderAt{File: file, size: fileInfo.Size()}, nil
+}
+
+type localReaderAt struct {
+	*os.File
+	size int64
+}
+
+func (r *localReaderAt) Size() int64 {
+	return r.size
+}
+
 // LocalGetFullPath Returns the full path of a file using the provided relative
 // file path to the local backups directory.
 func (Client) LocalGetFullPath(relativeFilePath string) string {
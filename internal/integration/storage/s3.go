@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -73,6 +74,9 @@ func (Client) S3Test(
 
 // S3Upload uploads a file to S3 from a reader.
 //
+// If the reader fails, for example because the backup was cancelled, the
+// parts of the multipart upload already sent are discarded.
+//
 // Returns the file size, in bytes.
 func (Client) S3Upload(
 	accessKey, secretKey, region, endpoint, bucketName, key string,
@@ -148,6 +152,157 @@ func (Client) S3Delete(
 	return nil
 }
 
+// S3Download returns a reader for the content of a file stored in S3.
+//
+// The caller must close the returned reader.
+func (Client) S3Download(
+	accessKey, secretKey, region, endpoint, bucketName, key string,
+) (io.ReadCloser, error) {
+	s3Client, err := createS3Client(
+		accessKey, secretKey, region, endpoint,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	object, err := s3Client.GetObject(
+		context.TODO(),
+		&s3.GetObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(key),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from S3: %w", err)
+	}
+
+	return object.Body, nil
+}
+
+// S3OpenAt returns a reader for a file stored in S3 that can be read at any
+// offset using ranged requests.
+//
+// The caller must close the returned reader.
+func (Client) S3OpenAt(
+	accessKey, secretKey, region, endpoint, bucketName, key string,
+) (ReaderAt, error) {
+	s3Client, err := createS3Client(
+		accessKey, secretKey, region, endpoint,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	fileHead, err := s3Client.HeadObject(
+		context.TODO(),
+		&s3.HeadObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(key),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info from S3: %w", err)
+	}
+
+	var size int64
+	if fileHead.ContentLength != nil {
+		size = *fileHead.ContentLength
+	}
+
+	return &s3ReaderAt{
+		client: s3Client,
+		bucket: bucketName,
+		key:    key,
+		size:   size,
+	}, nil
+}
+
+// s3ReadAhead is the minimum number of bytes requested to S3 by s3ReaderAt,
+// so sequential small reads don't make a request each.
+const s3ReadAhead = 8 << 20
+
+type s3ReaderAt struct {
+	client *s3.Client
+	bucket string
+	key    string
+	size   int64
+
+	mu       sync.Mutex
+	chunk    []byte
+	chunkOff int64
+}
+
+func (r *s3ReaderAt) Size() int64 {
+	return r.size
+}
+
+func (r *s3ReaderAt) Close() error {
+	return nil
+}
+
+func (r *s3ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for n < len(p) && off < r.size {
+		inChunk := off >= r.chunkOff && off < r.chunkOff+int64(len(r.chunk))
+		if !inChunk {
+			if err := r.fetch(off, int64(len(p)-n)); err != nil {
+				return n, err
+			}
+		}
+
+		copied := copy(p[n:], r.chunk[off-r.chunkOff:])
+		n += copied
+		off += int64(copied)
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// fetch downloads the range of the file starting at off into the chunk.
+func (r *s3ReaderAt) fetch(off int64, length int64) error {
+	length = max(length, s3ReadAhead)
+	end := min(off+length, r.size) - 1
+
+	object, err := r.client.GetObject(
+		context.TODO(),
+		&s3.GetObjectInput{
+			Bucket: aws.String(r.bucket),
+			Key:    aws.String(r.key),
+			Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, end)),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to download file range from S3: %w", err)
+	}
+	defer object.Body.Close()
+
+	chunk, err := io.ReadAll(object.Body)
+	if err != nil {
+		return fmt.Errorf("failed to download file range from S3: %w", err)
+	}
+	if len(chunk) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+
+	r.chunk = chunk
+	r.chunkOff = off
+	return nil
+}
+
 // S3GetDownloadLink generates a presigned URL for downloading a file from S3
 func (Client) S3GetDownloadLink(
 	accessKey, secretKey, region, endpoint, bucketName, key string,
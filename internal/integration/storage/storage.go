@@ -1,7 +1,34 @@
 package storage
 
+import (
+	"errors"
+	"io"
+	"io/fs"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
 type Client struct{}
 
 func New() *Client {
 	return &Client{}
 }
+
+// ReaderAt is a stored file that can be read at any offset, it is needed to
+// read formats like ZIP that can't be streamed.
+type ReaderAt interface {
+	io.ReaderAt
+	io.Closer
+
+	// Size returns the size of the file, in bytes.
+	Size() int64
+}
+
+// IsNotExist returns true if the error was returned because the file doesn't
+// exist in the local backups directory or in the S3 bucket.
+func IsNotExist(err error) bool {
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+	return errors.Is(err, fs.ErrNotExist) ||
+		errors.As(err, &noSuchKey) || errors.As(err, &notFound)
+}
@@ -1,25 +1,37 @@
 package backups
 
 import (
+	"github.com/eduardolat/pgbackweb/internal/config"
 	"github.com/eduardolat/pgbackweb/internal/cron"
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/restorations"
+	"github.com/eduardolat/pgbackweb/internal/service/walsegments"
 )
 
 type Service struct {
-	dbgen             *dbgen.Queries
-	cr                *cron.Cron
-	executionsService *executions.Service
+	env                 config.Env
+	dbgen               *dbgen.Queries
+	cr                  *cron.Cron
+	executionsService   *executions.Service
+	restorationsService *restorations.Service
+	walSegmentsService  *walsegments.Service
 }
 
 func New(
+	env config.Env,
 	dbgen *dbgen.Queries,
 	cr *cron.Cron,
 	executionsService *executions.Service,
+	restorationsService *restorations.Service,
+	walSegmentsService *walsegments.Service,
 ) *Service {
 	return &Service{
-		dbgen:             dbgen,
-		cr:                cr,
-		executionsService: executionsService,
+		env:                 env,
+		dbgen:               dbgen,
+		cr:                  cr,
+		executionsService:   executionsService,
+		restorationsService: restorationsService,
+		walSegmentsService:  walSegmentsService,
 	}
 }
@@ -2,10 +2,13 @@ package backups
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/google/uuid"
 )
 
 func (s *Service) CreateBackup(
@@ -15,14 +18,169 @@ func (s *Service) CreateBackup(
 		return dbgen.Backup{}, fmt.Errorf("invalid cron expression")
 	}
 
+	if params.OptJobs > 1 && params.OptFormat != "directory" {
+		return dbgen.Backup{}, fmt.Errorf("parallel jobs require the directory format")
+	}
+
+	if params.Kind != "database" && params.OptFormat != "plain" {
+		return dbgen.Backup{}, fmt.Errorf("only database backups support archive formats")
+	}
+
+	if params.OptWalArchiving && params.Kind != "physical" {
+		return dbgen.Backup{}, fmt.Errorf("WAL archiving requires a physical backup")
+	}
+
+	if err := validateCompression(params.OptCompression, params.OptCompressionLevel); err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	if err := validateEncryption(params.EncryptionPassphrase); err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	// The dump options column doesn't accept NULL values
+	if params.OptDumpOptions == "" {
+		params.OptDumpOptions = "{}"
+	}
+	if err := validateDumpOptions(params.OptDumpOptions); err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	err := validateHooks(params.HookTimeoutSeconds, params.HookFailurePolicy)
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	if params.TimeoutMinutes < 0 {
+		return dbgen.Backup{}, fmt.Errorf("timeout can't be negative")
+	}
+
+	err = validateRetries(
+		params.RetryMaxAttempts, params.RetryInitialDelaySeconds,
+		params.RetryBackoffFactor,
+	)
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	err = validateVerification(
+		params.Kind, params.VerifyIsActive, params.VerifyCronExpression,
+		params.VerifyDatabaseID,
+	)
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	// The filter columns don't accept NULL values
+	params.OptSchemas = nonNilSlice(params.OptSchemas)
+	params.OptExcludeSchemas = nonNilSlice(params.OptExcludeSchemas)
+	params.OptTables = nonNilSlice(params.OptTables)
+	params.OptExcludeTables = nonNilSlice(params.OptExcludeTables)
+	params.OptExcludeTableData = nonNilSlice(params.OptExcludeTableData)
+	params.VerifyChecks = nonNilSlice(params.VerifyChecks)
+
+	params.EncryptionKey = s.env.PBW_ENCRYPTION_KEY
+
 	backup, err := s.dbgen.BackupsServiceCreateBackup(ctx, params)
 	if err != nil {
 		return backup, err
 	}
 
-	if !backup.IsActive {
-		return backup, s.jobRemove(backup.ID)
+	return backup, s.scheduleJobs(backup)
+}
+
+// validateCompression returns an error if the compression codec can't be
+// selected for a backup or the level is not accepted by the codec.
+func validateCompression(key string, level int16) error {
+	comp, err := postgres.Client{}.ParseCompression(key)
+	if err != nil {
+		return err
+	}
+
+	if comp == postgres.CompressionZip {
+		return fmt.Errorf("compression not allowed: %s", key)
 	}
 
-	return backup, s.jobUpsert(backup.ID, backup.TimeZone, backup.CronExpression)
+	return comp.ValidateLevel(int(level))
+}
+
+// validateDumpOptions returns an error if the JSON document of the dump
+// options is not valid.
+func validateDumpOptions(data string) error {
+	_, err := postgres.Client{}.ParseDumpOptions([]byte(data))
+	return err
+}
+
+// validateHooks returns an error if the timeout or the failure policy of the
+// hooks of a backup are not valid.
+func validateHooks(timeoutSeconds int32, failurePolicy string) error {
+	if timeoutSeconds < 1 || timeoutSeconds > 86400 {
+		return fmt.Errorf("hook timeout must be between 1 and 86400 seconds")
+	}
+
+	if failurePolicy != "abort" && failurePolicy != "continue" {
+		return fmt.Errorf("invalid hook failure policy: %s", failurePolicy)
+	}
+
+	return nil
+}
+
+// validateRetries returns an error if the retry policy of a backup is not
+// valid.
+func validateRetries(
+	maxAttempts int32, initialDelaySeconds int32, backoffFactor float64,
+) error {
+	if maxAttempts < 1 || maxAttempts > 10 {
+		return fmt.Errorf("max attempts must be between 1 and 10")
+	}
+
+	if initialDelaySeconds < 1 || initialDelaySeconds > 86400 {
+		return fmt.Errorf("retry delay must be between 1 and 86400 seconds")
+	}
+
+	if backoffFactor < 1 || backoffFactor > 10 {
+		return fmt.Errorf("backoff factor must be between 1 and 10")
+	}
+
+	return nil
+}
+
+// validateVerification returns an error if the restore drills of a backup
+// are active but can't run.
+func validateVerification(
+	kind string, isActive bool, cronExpression string, databaseID uuid.NullUUID,
+) error {
+	if !isActive {
+		return nil
+	}
+
+	if kind != "database" {
+		return fmt.Errorf("only database backups can be verified")
+	}
+
+	if !validate.CronExpression(cronExpression) {
+		return fmt.Errorf("invalid verification cron expression")
+	}
+
+	if !databaseID.Valid {
+		return fmt.Errorf("verification server is required")
+	}
+
+	return nil
+}
+
+// validateEncryption returns an error if a new encryption passphrase is set
+// but empty.
+func validateEncryption(passphrase sql.NullString) error {
+	if passphrase.Valid && passphrase.String == "" {
+		return fmt.Errorf("encryption passphrase is required")
+	}
+	return nil
+}
+
+func nonNilSlice(slice []string) []string {
+	if slice == nil {
+		return []string{}
+	}
+	return slice
 }
@@ -2,11 +2,34 @@
 INSERT INTO backups (
   database_id, destination_id, is_local, name, cron_expression, time_zone,
   is_active, dest_dir, retention_days, opt_data_only, opt_schema_only,
-  opt_clean, opt_if_exists, opt_create, opt_no_comments
+  opt_clean, opt_if_exists, opt_create, opt_no_comments, opt_format, opt_jobs,
+  opt_schemas, opt_exclude_schemas, opt_tables, opt_exclude_tables,
+  opt_exclude_table_data, kind, opt_wal_archiving, opt_compression,
+  opt_compression_level, verify_is_active, verify_cron_expression,
+  verify_database_id, verify_checks, encryption_passphrase, opt_dump_options,
+  pre_hook_sql, pre_hook_command, post_hook_sql, post_hook_command,
+  hook_timeout_seconds, hook_failure_policy, timeout_minutes,
+  retry_max_attempts, retry_initial_delay_seconds, retry_backoff_factor
 )
 VALUES (
   @database_id, @destination_id, @is_local, @name, @cron_expression, @time_zone,
   @is_active, @dest_dir, @retention_days, @opt_data_only, @opt_schema_only,
-  @opt_clean, @opt_if_exists, @opt_create, @opt_no_comments
+  @opt_clean, @opt_if_exists, @opt_create, @opt_no_comments, @opt_format, @opt_jobs,
+  @opt_schemas, @opt_exclude_schemas, @opt_tables, @opt_exclude_tables,
+  @opt_exclude_table_data, @kind, @opt_wal_archiving, @opt_compression,
+  @opt_compression_level, @verify_is_active, @verify_cron_expression,
+  @verify_database_id, @verify_checks,
+  (
+    CASE WHEN sqlc.narg('encryption_passphrase')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('encryption_passphrase')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE NULL
+    END
+  ),
+  sqlc.arg('opt_dump_options')::TEXT::JSONB,
+  @pre_hook_sql, @pre_hook_command, @post_hook_sql, @post_hook_command,
+  @hook_timeout_seconds, @hook_failure_policy, @timeout_minutes,
+  @retry_max_attempts, @retry_initial_delay_seconds, @retry_backoff_factor
 )
 RETURNING *;
@@ -9,10 +9,24 @@ import (
 func (s *Service) DeleteBackup(
 	ctx context.Context, id uuid.UUID,
 ) error {
-	err := s.jobRemove(id)
+	backup, err := s.dbgen.BackupsServiceGetBackup(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	err = s.jobRemove(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.verificationJobRemove(id)
+	if err != nil {
+		return err
+	}
+
+	if backup.Kind == "physical" && backup.OptWalArchiving {
+		s.stopArchiving(ctx, backup)
+	}
+
 	return s.dbgen.BackupsServiceDeleteBackup(ctx, id)
 }
@@ -5,3 +5,7 @@ import "github.com/google/uuid"
 func (s *Service) jobRemove(backupID uuid.UUID) error {
 	return s.cr.RemoveJob(backupID)
 }
+
+func (s *Service) verificationJobRemove(backupID uuid.UUID) error {
+	return s.cr.RemoveJob(verificationJobID(backupID))
+}
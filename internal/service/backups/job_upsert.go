@@ -14,3 +14,19 @@ func (s *Service) jobUpsert(
 		s.executionsService.RunExecution, context.Background(), backupID,
 	)
 }
+
+// verificationJobUpsert schedules the restore drills of the backup, the job
+// ID is derived from the backup ID so both jobs can coexist.
+func (s *Service) verificationJobUpsert(
+	backupID uuid.UUID, timeZone string, cronExpression string,
+) error {
+	return s.cr.UpsertJob(
+		verificationJobID(backupID), timeZone, cronExpression,
+		s.restorationsService.RunVerification, context.Background(), backupID,
+	)
+}
+
+// verificationJobID returns the ID of the verification job of the backup.
+func verificationJobID(backupID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(backupID, []byte("verification"))
+}
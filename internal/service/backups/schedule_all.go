@@ -28,6 +28,15 @@ func (s *Service) ScheduleAll() {
 				logger.Error("error scheduling backup", logger.KV{"error": err})
 			}
 		}
+
+		if backup.IsActive && backup.VerifyIsActive {
+			err := s.verificationJobUpsert(
+				backup.ID, backup.TimeZone, backup.VerifyCronExpression,
+			)
+			if err != nil {
+				logger.Error("error scheduling verification", logger.KV{"error": err})
+			}
+		}
 	}
 
 	logger.Info("all active backups scheduled")
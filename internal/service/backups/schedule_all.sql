@@ -3,6 +3,8 @@ SELECT
   id,
   is_active,
   cron_expression,
-  time_zone
+  time_zone,
+  verify_is_active,
+  verify_cron_expression
 FROM backups
 ORDER BY created_at DESC;
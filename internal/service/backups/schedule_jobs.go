@@ -0,0 +1,31 @@
+package backups
+
+import (
+	"errors"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+)
+
+// scheduleJobs upserts or removes the backup and verification jobs of the
+// backup depending on whether they are active. Verifications only run while
+// the backup is active.
+func (s *Service) scheduleJobs(backup dbgen.Backup) error {
+	if !backup.IsActive {
+		return errors.Join(
+			s.jobRemove(backup.ID), s.verificationJobRemove(backup.ID),
+		)
+	}
+
+	err := s.jobUpsert(backup.ID, backup.TimeZone, backup.CronExpression)
+	if err != nil {
+		return err
+	}
+
+	if !backup.VerifyIsActive {
+		return s.verificationJobRemove(backup.ID)
+	}
+
+	return s.verificationJobUpsert(
+		backup.ID, backup.TimeZone, backup.VerifyCronExpression,
+	)
+}
@@ -0,0 +1,27 @@
+package backups
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+)
+
+// isArchivingWAL returns true if the WAL of the backup is being archived.
+func isArchivingWAL(backup dbgen.Backup) bool {
+	return backup.IsActive && backup.Kind == "physical" && backup.OptWalArchiving
+}
+
+// stopArchiving stops the WAL archiving of the backup and drops its
+// replication slot. A failure is logged instead of returned, so a server that
+// is no longer reachable doesn't prevent changing or deleting the backup, the
+// slot must then be dropped by hand.
+func (s *Service) stopArchiving(ctx context.Context, backup dbgen.Backup) {
+	err := s.walSegmentsService.StopArchiving(ctx, backup.ID)
+	if err != nil {
+		logger.Error("error stopping WAL archiving", logger.KV{
+			"backup_id": backup.ID.String(),
+			"error":     err,
+		})
+	}
+}
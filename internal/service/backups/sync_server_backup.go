@@ -0,0 +1,77 @@
+package backups
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+// SyncServerBackupParams contains the parameters of SyncServerBackup.
+type SyncServerBackupParams struct {
+	ServerID         uuid.UUID
+	TemplateBackupID uuid.UUID
+	DatabaseID       uuid.UUID
+
+	// DatabaseName is the name of the database in the server, it is appended
+	// to the name and the destination directory of the template.
+	DatabaseName string
+
+	// Force updates the backup even if the template didn't change since the
+	// backup was last updated.
+	Force bool
+}
+
+// SyncServerBackup creates the backup of a database discovered in a server
+// by copying the template backup of the server, or updates it with the
+// settings of the template if the template changed after the backup. The
+// destination, the database and whether the backup is active are kept.
+func (s *Service) SyncServerBackup(
+	ctx context.Context, params SyncServerBackupParams,
+) error {
+	backupID, err := s.dbgen.BackupsServiceGetServerBackupID(
+		ctx, dbgen.BackupsServiceGetServerBackupIDParams{
+			ServerID:   uuid.NullUUID{UUID: params.ServerID, Valid: true},
+			DatabaseID: params.DatabaseID,
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		backup, err := s.dbgen.BackupsServiceCreateServerBackup(
+			ctx, dbgen.BackupsServiceCreateServerBackupParams{
+				DatabaseID:       params.DatabaseID,
+				ServerID:         params.ServerID,
+				DatabaseName:     params.DatabaseName,
+				TemplateBackupID: params.TemplateBackupID,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		return s.scheduleJobs(backup)
+	}
+	if err != nil {
+		return err
+	}
+
+	backups, err := s.dbgen.BackupsServiceUpdateServerBackup(
+		ctx, dbgen.BackupsServiceUpdateServerBackupParams{
+			DatabaseName:     params.DatabaseName,
+			BackupID:         backupID,
+			TemplateBackupID: params.TemplateBackupID,
+			Force:            params.Force,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, backup := range backups {
+		if err := s.scheduleJobs(backup); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+-- name: BackupsServiceGetServerBackupID :one
+SELECT id FROM backups
+WHERE server_id = @server_id AND database_id = @database_id;
+
+-- name: BackupsServiceCreateServerBackup :one
+INSERT INTO backups
+SELECT (
+  backups
+  #= hstore('id', uuid_generate_v4()::text)
+  #= hstore('database_id', sqlc.arg('database_id')::UUID::text)
+  #= hstore('server_id', sqlc.arg('server_id')::UUID::text)
+  #= hstore('name', (backups.name || ' - ' || sqlc.arg('database_name')::TEXT)::text)
+  #= hstore('dest_dir', (backups.dest_dir || '/' || sqlc.arg('database_name')::TEXT)::text)
+  #= hstore('is_active', true::text)
+  #= hstore('created_at', now()::text)
+  #= hstore('updated_at', now()::text)
+).*
+FROM backups
+WHERE backups.id = @template_backup_id
+RETURNING *;
+
+-- name: BackupsServiceUpdateServerBackup :many
+UPDATE backups
+SET
+  name = template.name || ' - ' || sqlc.arg('database_name')::TEXT,
+  cron_expression = template.cron_expression,
+  time_zone = template.time_zone,
+  dest_dir = template.dest_dir || '/' || sqlc.arg('database_name')::TEXT,
+  retention_days = template.retention_days,
+  opt_data_only = template.opt_data_only,
+  opt_schema_only = template.opt_schema_only,
+  opt_clean = template.opt_clean,
+  opt_if_exists = template.opt_if_exists,
+  opt_create = template.opt_create,
+  opt_no_comments = template.opt_no_comments,
+  opt_format = template.opt_format,
+  opt_jobs = template.opt_jobs,
+  opt_schemas = template.opt_schemas,
+  opt_exclude_schemas = template.opt_exclude_schemas,
+  opt_tables = template.opt_tables,
+  opt_exclude_tables = template.opt_exclude_tables,
+  opt_exclude_table_data = template.opt_exclude_table_data,
+  opt_wal_archiving = template.opt_wal_archiving,
+  opt_compression = template.opt_compression,
+  opt_compression_level = template.opt_compression_level,
+  verify_is_active = template.verify_is_active,
+  verify_cron_expression = template.verify_cron_expression,
+  verify_database_id = template.verify_database_id,
+  verify_checks = template.verify_checks,
+  opt_dump_options = template.opt_dump_options,
+  encryption_passphrase = template.encryption_passphrase,
+  pre_hook_sql = template.pre_hook_sql,
+  pre_hook_command = template.pre_hook_command,
+  post_hook_sql = template.post_hook_sql,
+  post_hook_command = template.post_hook_command,
+  hook_timeout_seconds = template.hook_timeout_seconds,
+  hook_failure_policy = template.hook_failure_policy,
+  timeout_minutes = template.timeout_minutes,
+  retry_max_attempts = template.retry_max_attempts,
+  retry_initial_delay_seconds = template.retry_initial_delay_seconds,
+  retry_backoff_factor = template.retry_backoff_factor
+FROM backups AS template
+WHERE backups.id = @backup_id
+AND template.id = @template_backup_id
+AND (
+  sqlc.arg('force')::BOOLEAN
+  OR COALESCE(template.updated_at, template.created_at)
+    > COALESCE(backups.updated_at, backups.created_at)
+)
+RETURNING backups.*;
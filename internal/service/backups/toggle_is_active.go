@@ -12,9 +12,9 @@ func (s *Service) ToggleIsActive(ctx context.Context, backupID uuid.UUID) error
 		return err
 	}
 
-	if !backup.IsActive {
-		return s.jobRemove(backupID)
+	if !backup.IsActive && backup.Kind == "physical" && backup.OptWalArchiving {
+		s.stopArchiving(ctx, backup)
 	}
 
-	return s.jobUpsert(backupID, backup.TimeZone, backup.CronExpression)
+	return s.scheduleJobs(backup)
 }
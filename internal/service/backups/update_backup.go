@@ -15,14 +15,115 @@ func (s *Service) UpdateBackup(
 		return dbgen.Backup{}, fmt.Errorf("invalid cron expression")
 	}
 
+	// The settings that are validated together are merged with the current
+	// ones when only some of them change
+	current, err := s.dbgen.BackupsServiceGetBackup(ctx, params.ID)
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	if params.OptJobs.Int16 > 1 && params.OptFormat.Valid &&
+		params.OptFormat.String != "directory" {
+		return dbgen.Backup{}, fmt.Errorf("parallel jobs require the directory format")
+	}
+
+	if params.OptCompression.Valid || params.OptCompressionLevel.Valid {
+		compression := current.OptCompression
+		if params.OptCompression.Valid {
+			compression = params.OptCompression.String
+		}
+		compressionLevel := current.OptCompressionLevel
+		if params.OptCompressionLevel.Valid {
+			compressionLevel = params.OptCompressionLevel.Int16
+		}
+
+		if err := validateCompression(compression, compressionLevel); err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
+	if err := validateEncryption(params.EncryptionPassphrase); err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	if params.OptDumpOptions.Valid {
+		if err := validateDumpOptions(params.OptDumpOptions.String); err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
+	if params.TimeoutMinutes.Valid && params.TimeoutMinutes.Int32 < 0 {
+		return dbgen.Backup{}, fmt.Errorf("timeout can't be negative")
+	}
+
+	if params.HookTimeoutSeconds.Valid || params.HookFailurePolicy.Valid {
+		timeoutSeconds := current.HookTimeoutSeconds
+		if params.HookTimeoutSeconds.Valid {
+			timeoutSeconds = params.HookTimeoutSeconds.Int32
+		}
+		failurePolicy := current.HookFailurePolicy
+		if params.HookFailurePolicy.Valid {
+			failurePolicy = params.HookFailurePolicy.String
+		}
+
+		if err := validateHooks(timeoutSeconds, failurePolicy); err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
+	if params.RetryMaxAttempts.Valid || params.RetryInitialDelaySeconds.Valid ||
+		params.RetryBackoffFactor.Valid {
+		maxAttempts := current.RetryMaxAttempts
+		if params.RetryMaxAttempts.Valid {
+			maxAttempts = params.RetryMaxAttempts.Int32
+		}
+		initialDelaySeconds := current.RetryInitialDelaySeconds
+		if params.RetryInitialDelaySeconds.Valid {
+			initialDelaySeconds = params.RetryInitialDelaySeconds.Int32
+		}
+		backoffFactor := current.RetryBackoffFactor
+		if params.RetryBackoffFactor.Valid {
+			backoffFactor = params.RetryBackoffFactor.Float64
+		}
+
+		err = validateRetries(maxAttempts, initialDelaySeconds, backoffFactor)
+		if err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
+	if params.VerifyIsActive.Valid || params.VerifyCronExpression.Valid ||
+		params.VerifyDatabaseID.Valid {
+		isActive := current.VerifyIsActive
+		if params.VerifyIsActive.Valid {
+			isActive = params.VerifyIsActive.Bool
+		}
+		cronExpression := current.VerifyCronExpression
+		if params.VerifyCronExpression.Valid {
+			cronExpression = params.VerifyCronExpression.String
+		}
+		databaseID := current.VerifyDatabaseID
+		if params.VerifyDatabaseID.Valid {
+			databaseID = params.VerifyDatabaseID
+		}
+
+		err = validateVerification(
+			current.Kind, isActive, cronExpression, databaseID,
+		)
+		if err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
+	params.EncryptionKey = s.env.PBW_ENCRYPTION_KEY
 	backup, err := s.dbgen.BackupsServiceUpdateBackup(ctx, params)
 	if err != nil {
 		return backup, err
 	}
 
-	if !backup.IsActive {
-		return backup, s.jobRemove(backup.ID)
+	if isArchivingWAL(current) && !isArchivingWAL(backup) {
+		s.stopArchiving(ctx, backup)
 	}
 
-	return backup, s.jobUpsert(backup.ID, backup.TimeZone, backup.CronExpression)
+	return backup, s.scheduleJobs(backup)
 }
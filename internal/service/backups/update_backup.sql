@@ -12,6 +12,39 @@ SET
   opt_clean = COALESCE(sqlc.narg('opt_clean'), opt_clean),
   opt_if_exists = COALESCE(sqlc.narg('opt_if_exists'), opt_if_exists),
   opt_create = COALESCE(sqlc.narg('opt_create'), opt_create),
-  opt_no_comments = COALESCE(sqlc.narg('opt_no_comments'), opt_no_comments)
+  opt_no_comments = COALESCE(sqlc.narg('opt_no_comments'), opt_no_comments),
+  opt_format = COALESCE(sqlc.narg('opt_format'), opt_format),
+  opt_jobs = COALESCE(sqlc.narg('opt_jobs'), opt_jobs),
+  opt_schemas = COALESCE(sqlc.narg('opt_schemas')::TEXT[], opt_schemas),
+  opt_exclude_schemas = COALESCE(sqlc.narg('opt_exclude_schemas')::TEXT[], opt_exclude_schemas),
+  opt_tables = COALESCE(sqlc.narg('opt_tables')::TEXT[], opt_tables),
+  opt_exclude_tables = COALESCE(sqlc.narg('opt_exclude_tables')::TEXT[], opt_exclude_tables),
+  opt_exclude_table_data = COALESCE(sqlc.narg('opt_exclude_table_data')::TEXT[], opt_exclude_table_data),
+  opt_wal_archiving = COALESCE(sqlc.narg('opt_wal_archiving'), opt_wal_archiving),
+  opt_compression = COALESCE(sqlc.narg('opt_compression'), opt_compression),
+  opt_compression_level = COALESCE(sqlc.narg('opt_compression_level'), opt_compression_level),
+  verify_is_active = COALESCE(sqlc.narg('verify_is_active'), verify_is_active),
+  verify_cron_expression = COALESCE(sqlc.narg('verify_cron_expression'), verify_cron_expression),
+  verify_database_id = COALESCE(sqlc.narg('verify_database_id'), verify_database_id),
+  verify_checks = COALESCE(sqlc.narg('verify_checks')::TEXT[], verify_checks),
+  opt_dump_options = COALESCE(sqlc.narg('opt_dump_options')::TEXT::JSONB, opt_dump_options),
+  pre_hook_sql = COALESCE(sqlc.narg('pre_hook_sql'), pre_hook_sql),
+  pre_hook_command = COALESCE(sqlc.narg('pre_hook_command'), pre_hook_command),
+  post_hook_sql = COALESCE(sqlc.narg('post_hook_sql'), post_hook_sql),
+  post_hook_command = COALESCE(sqlc.narg('post_hook_command'), post_hook_command),
+  hook_timeout_seconds = COALESCE(sqlc.narg('hook_timeout_seconds'), hook_timeout_seconds),
+  hook_failure_policy = COALESCE(sqlc.narg('hook_failure_policy'), hook_failure_policy),
+  timeout_minutes = COALESCE(sqlc.narg('timeout_minutes'), timeout_minutes),
+  retry_max_attempts = COALESCE(sqlc.narg('retry_max_attempts'), retry_max_attempts),
+  retry_initial_delay_seconds = COALESCE(sqlc.narg('retry_initial_delay_seconds'), retry_initial_delay_seconds),
+  retry_backoff_factor = COALESCE(sqlc.narg('retry_backoff_factor'), retry_backoff_factor),
+  encryption_passphrase = CASE
+    WHEN sqlc.narg('remove_encryption')::BOOLEAN IS TRUE THEN NULL
+    WHEN sqlc.narg('encryption_passphrase')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('encryption_passphrase')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE encryption_passphrase
+  END
 WHERE id = @id
 RETURNING *;
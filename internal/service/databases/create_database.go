@@ -4,15 +4,31 @@ import (
 	"context"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 )
 
 func (s *Service) CreateDatabase(
 	ctx context.Context, params dbgen.DatabasesServiceCreateDatabaseParams,
 ) (dbgen.Database, error) {
-	err := s.TestDatabase(ctx, params.PgVersion, params.ConnectionString)
+	version, err := s.TestDatabaseWithParams(
+		ctx, params.ConnectionString, postgres.ConnectionParams{
+			SSHTunnel: postgres.SSHTunnelParams{
+				Host:       params.SshHost.String,
+				User:       params.SshUser.String,
+				PrivateKey: params.SshPrivateKey.String,
+				HostKey:    params.SshHostKey.String,
+			},
+			TLS: postgres.TLSParams{
+				RootCert:   params.TlsRootCert.String,
+				ClientCert: params.TlsClientCert.String,
+				ClientKey:  params.TlsClientKey.String,
+			},
+		},
+	)
 	if err != nil {
 		return dbgen.Database{}, err
 	}
+	params.PgVersion = version.Value.Version
 
 	params.EncryptionKey = s.env.PBW_ENCRYPTION_KEY
 	db, err := s.dbgen.DatabasesServiceCreateDatabase(ctx, params)
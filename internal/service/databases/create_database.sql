@@ -1,8 +1,44 @@
 -- name: DatabasesServiceCreateDatabase :one
 INSERT INTO databases (
-  name, connection_string, pg_version
+  name, connection_string, pg_version,
+  ssh_host, ssh_user, ssh_private_key, ssh_host_key,
+  tls_root_cert, tls_client_cert, tls_client_key
 )
 VALUES (
-  @name, pgp_sym_encrypt(@connection_string, @encryption_key), @pg_version
+  @name, pgp_sym_encrypt(@connection_string, @encryption_key), @pg_version,
+  sqlc.narg('ssh_host'), sqlc.narg('ssh_user'),
+  (
+    CASE WHEN sqlc.narg('ssh_private_key')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('ssh_private_key')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE NULL
+    END
+  ),
+  sqlc.narg('ssh_host_key'),
+  (
+    CASE WHEN sqlc.narg('tls_root_cert')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('tls_root_cert')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE NULL
+    END
+  ),
+  (
+    CASE WHEN sqlc.narg('tls_client_cert')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('tls_client_cert')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE NULL
+    END
+  ),
+  (
+    CASE WHEN sqlc.narg('tls_client_key')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('tls_client_key')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE NULL
+    END
+  )
 )
 RETURNING *;
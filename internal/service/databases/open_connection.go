@@ -0,0 +1,49 @@
+package databases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/google/uuid"
+)
+
+// ConnectionParams returns the SSH tunnel and TLS settings of a database,
+// the tunnel is disabled if the SSH host is empty.
+func ConnectionParams(
+	db dbgen.DatabasesServiceGetDatabaseRow,
+) postgres.ConnectionParams {
+	return postgres.ConnectionParams{
+		SSHTunnel: postgres.SSHTunnelParams{
+			Host:       db.SshHost.String,
+			User:       db.SshUser.String,
+			PrivateKey: db.DecryptedSshPrivateKey,
+			HostKey:    db.SshHostKey.String,
+		},
+		TLS: postgres.TLSParams{
+			RootCert:   db.DecryptedTlsRootCert,
+			ClientCert: db.DecryptedTlsClientCert,
+			ClientKey:  db.DecryptedTlsClientKey,
+		},
+	}
+}
+
+// OpenConnection opens the SSH tunnel and writes the TLS files of the
+// database, the connection string of the returned connection must be used to
+// connect to the database while it is open. If the database has neither, the
+// connection uses the connection string of the database.
+//
+// The connection must be closed when it is no longer used.
+func (s *Service) OpenConnection(
+	ctx context.Context, databaseID uuid.UUID,
+) (*postgres.Connection, error) {
+	db, err := s.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting database: %w", err)
+	}
+
+	return s.ints.PGClient.OpenConnection(
+		db.DecryptedConnectionString, ConnectionParams(db),
+	)
+}
@@ -4,7 +4,31 @@ SELECT COUNT(*) FROM databases;
 -- name: DatabasesServicePaginateDatabases :many
 SELECT
   *,
-  pgp_sym_decrypt(connection_string, @encryption_key) AS decrypted_connection_string
+  pgp_sym_decrypt(connection_string, @encryption_key) AS decrypted_connection_string,
+  (
+    CASE WHEN ssh_private_key IS NOT NULL
+    THEN pgp_sym_decrypt(ssh_private_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_ssh_private_key,
+  (
+    CASE WHEN tls_root_cert IS NOT NULL
+    THEN pgp_sym_decrypt(tls_root_cert, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_tls_root_cert,
+  (
+    CASE WHEN tls_client_cert IS NOT NULL
+    THEN pgp_sym_decrypt(tls_client_cert, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_tls_client_cert,
+  (
+    CASE WHEN tls_client_key IS NOT NULL
+    THEN pgp_sym_decrypt(tls_client_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_tls_client_key
 FROM databases
 ORDER BY created_at DESC
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,47 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// SyncPgVersion stores the detected PostgreSQL version of the database server
+// if it differs from the stored one, along with a warning so users know the
+// server has been upgraded (or downgraded) since the last run.
+//
+// The warning is cleared by the next run that detects the stored version.
+func (s *Service) SyncPgVersion(
+	ctx context.Context, databaseID uuid.UUID, storedVersion string,
+	detectedVersion postgres.PGVersion,
+) error {
+	if storedVersion == detectedVersion.Value.Version {
+		return s.dbgen.DatabasesServiceClearPgVersionWarning(ctx, databaseID)
+	}
+
+	warning := fmt.Sprintf(
+		"The server version changed from PostgreSQL %s to %s on %s, the v%s "+
+			"binaries are used from now on",
+		storedVersion, detectedVersion.Value.Version,
+		time.Now().Format(time.RFC3339), detectedVersion.Value.Version,
+	)
+	logger.Warn("database server version changed", logger.KV{
+		"database_id":      databaseID.String(),
+		"previous_version": storedVersion,
+		"detected_version": detectedVersion.Value.Version,
+	})
+
+	return s.dbgen.DatabasesServiceSetPgVersion(
+		ctx, dbgen.DatabasesServiceSetPgVersionParams{
+			DatabaseID:       databaseID,
+			PgVersion:        detectedVersion.Value.Version,
+			PgVersionWarning: sql.NullString{Valid: true, String: warning},
+		},
+	)
+}
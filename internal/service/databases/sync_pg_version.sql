@@ -0,0 +1,10 @@
+-- name: DatabasesServiceSetPgVersion :exec
+UPDATE databases
+SET pg_version = @pg_version,
+    pg_version_warning = @pg_version_warning
+WHERE id = @database_id;
+
+-- name: DatabasesServiceClearPgVersionWarning :exec
+UPDATE databases
+SET pg_version_warning = NULL
+WHERE id = @database_id AND pg_version_warning IS NOT NULL;
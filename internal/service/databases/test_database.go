@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/google/uuid"
 )
 
@@ -36,7 +37,9 @@ func (s *Service) TestDatabaseAndStoreResult(
 		return storeRes(false, fmt.Errorf("error getting database: %w", err))
 	}
 
-	err = s.TestDatabase(ctx, db.PgVersion, db.DecryptedConnectionString)
+	version, err := s.TestDatabaseWithParams(
+		ctx, db.DecryptedConnectionString, ConnectionParams(db),
+	)
 	if err != nil && db.TestOk.Valid && db.TestOk.Bool {
 		s.webhooksService.RunDatabaseUnhealthy(db.ID)
 	}
@@ -44,24 +47,43 @@ func (s *Service) TestDatabaseAndStoreResult(
 		return storeRes(false, err)
 	}
 
+	err = s.SyncPgVersion(ctx, db.ID, db.PgVersion, version)
+	if err != nil {
+		return storeRes(false, err)
+	}
+
 	if db.TestOk.Valid && !db.TestOk.Bool {
 		s.webhooksService.RunDatabaseHealthy(db.ID)
 	}
 	return storeRes(true, nil)
 }
 
+// TestDatabase tests the connection to the database and returns the detected
+// PostgreSQL version of the server.
 func (s *Service) TestDatabase(
-	ctx context.Context, version, connString string,
-) error {
-	pgVersion, err := s.ints.PGClient.ParseVersion(version)
+	ctx context.Context, connString string,
+) (postgres.PGVersion, error) {
+	return s.TestDatabaseWithParams(
+		ctx, connString, postgres.ConnectionParams{},
+	)
+}
+
+// TestDatabaseWithParams tests the connection to the database through the
+// SSH tunnel and with the TLS files of the params, if they are set, and
+// returns the detected PostgreSQL version of the server.
+func (s *Service) TestDatabaseWithParams(
+	ctx context.Context, connString string, params postgres.ConnectionParams,
+) (postgres.PGVersion, error) {
+	conn, err := s.ints.PGClient.OpenConnection(connString, params)
 	if err != nil {
-		return fmt.Errorf("error parsing PostgreSQL version: %w", err)
+		return postgres.PGVersion{}, fmt.Errorf("error testing database: %w", err)
 	}
+	defer conn.Close()
 
-	err = s.ints.PGClient.Test(pgVersion, connString)
+	version, err := s.ints.PGClient.Test(ctx, conn.ConnString)
 	if err != nil {
-		return fmt.Errorf("error testing database: %w", err)
+		return postgres.PGVersion{}, fmt.Errorf("error testing database: %w", err)
 	}
 
-	return nil
+	return version, nil
 }
@@ -2,21 +2,58 @@ package databases
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 )
 
 // UpdateDatabase updates an existing database entry.
 func (s *Service) UpdateDatabase(
 	ctx context.Context, params dbgen.DatabasesServiceUpdateDatabaseParams,
 ) (dbgen.Database, error) {
-	err := s.TestDatabase(
-		ctx, params.PgVersion.String, params.ConnectionString.String,
-	)
+	current, err := s.GetDatabase(ctx, params.ID)
 	if err != nil {
 		return dbgen.Database{}, err
 	}
 
+	// The connection is tested with the settings the database will have after
+	// the update, the ones not sent are kept
+	connString := current.DecryptedConnectionString
+	if params.ConnectionString.Valid {
+		connString = params.ConnectionString.String
+	}
+	connParams := ConnectionParams(current)
+	if params.RemoveSshTunnel.Bool {
+		connParams.SSHTunnel = postgres.SSHTunnelParams{}
+	} else {
+		if params.SshHost.Valid {
+			connParams.SSHTunnel.Host = params.SshHost.String
+		}
+		if params.SshUser.Valid {
+			connParams.SSHTunnel.User = params.SshUser.String
+		}
+		if params.SshPrivateKey.Valid {
+			connParams.SSHTunnel.PrivateKey = params.SshPrivateKey.String
+		}
+		if params.SshHostKey.Valid {
+			connParams.SSHTunnel.HostKey = params.SshHostKey.String
+		}
+	}
+	if params.UpdateTlsFiles.Bool {
+		connParams.TLS = postgres.TLSParams{
+			RootCert:   params.TlsRootCert.String,
+			ClientCert: params.TlsClientCert.String,
+			ClientKey:  params.TlsClientKey.String,
+		}
+	}
+
+	version, err := s.TestDatabaseWithParams(ctx, connString, connParams)
+	if err != nil {
+		return dbgen.Database{}, err
+	}
+	params.PgVersion = sql.NullString{Valid: true, String: version.Value.Version}
+
 	params.EncryptionKey = s.env.PBW_ENCRYPTION_KEY
 	db, err := s.dbgen.DatabasesServiceUpdateDatabase(ctx, params)
 
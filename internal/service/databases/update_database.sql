@@ -3,12 +3,60 @@ UPDATE databases
 SET
   name = COALESCE(sqlc.narg('name'), name),
   pg_version = COALESCE(sqlc.narg('pg_version'), pg_version),
+  pg_version_warning = CASE
+    WHEN sqlc.narg('pg_version') IS NOT NULL THEN NULL
+    ELSE pg_version_warning
+  END,
   connection_string = CASE
     WHEN sqlc.narg('connection_string')::TEXT IS NOT NULL
     THEN pgp_sym_encrypt(
       sqlc.narg('connection_string')::TEXT, sqlc.arg('encryption_key')::TEXT
     )
     ELSE connection_string
+  END,
+  ssh_host = CASE
+    WHEN sqlc.narg('remove_ssh_tunnel')::BOOLEAN IS TRUE THEN NULL
+    ELSE COALESCE(sqlc.narg('ssh_host'), ssh_host)
+  END,
+  ssh_user = CASE
+    WHEN sqlc.narg('remove_ssh_tunnel')::BOOLEAN IS TRUE THEN NULL
+    ELSE COALESCE(sqlc.narg('ssh_user'), ssh_user)
+  END,
+  ssh_private_key = CASE
+    WHEN sqlc.narg('remove_ssh_tunnel')::BOOLEAN IS TRUE THEN NULL
+    WHEN sqlc.narg('ssh_private_key')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('ssh_private_key')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE ssh_private_key
+  END,
+  ssh_host_key = CASE
+    WHEN sqlc.narg('remove_ssh_tunnel')::BOOLEAN IS TRUE THEN NULL
+    ELSE COALESCE(sqlc.narg('ssh_host_key'), ssh_host_key)
+  END,
+  tls_root_cert = CASE
+    WHEN sqlc.narg('update_tls_files')::BOOLEAN IS NOT TRUE THEN tls_root_cert
+    WHEN sqlc.narg('tls_root_cert')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('tls_root_cert')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE NULL
+  END,
+  tls_client_cert = CASE
+    WHEN sqlc.narg('update_tls_files')::BOOLEAN IS NOT TRUE THEN tls_client_cert
+    WHEN sqlc.narg('tls_client_cert')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('tls_client_cert')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE NULL
+  END,
+  tls_client_key = CASE
+    WHEN sqlc.narg('update_tls_files')::BOOLEAN IS NOT TRUE THEN tls_client_key
+    WHEN sqlc.narg('tls_client_key')::TEXT IS NOT NULL
+    THEN pgp_sym_encrypt(
+      sqlc.narg('tls_client_key')::TEXT, sqlc.arg('encryption_key')::TEXT
+    )
+    ELSE NULL
   END
 WHERE id = @id
 RETURNING *;
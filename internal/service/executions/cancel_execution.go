@@ -0,0 +1,51 @@
+package executions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// CancelExecution cancels a running execution. The backup process is killed,
+// the partial file is discarded and the execution is marked as cancelled by
+// RunExecution. Cancelling a failed execution whose retry is waiting for its
+// next attempt stops the retries.
+//
+// Executions left running by a previous process, for example after a
+// restart, are marked as cancelled right away.
+func (s *Service) CancelExecution(
+	ctx context.Context, executionID uuid.UUID,
+) error {
+	s.runningMu.Lock()
+	cancel, ok := s.running[executionID]
+	s.runningMu.Unlock()
+	if ok {
+		cancel()
+		return nil
+	}
+
+	_, err := s.dbgen.ExecutionsServiceCancelStaleExecution(ctx, executionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("execution is not running")
+	}
+	return err
+}
+
+// trackRunning registers the cancel function of a running execution, the
+// returned function must be called when the execution finishes.
+func (s *Service) trackRunning(
+	executionID uuid.UUID, cancel context.CancelFunc,
+) func() {
+	s.runningMu.Lock()
+	s.running[executionID] = cancel
+	s.runningMu.Unlock()
+
+	return func() {
+		s.runningMu.Lock()
+		delete(s.running, executionID)
+		s.runningMu.Unlock()
+	}
+}
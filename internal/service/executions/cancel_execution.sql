@@ -0,0 +1,8 @@
+-- name: ExecutionsServiceCancelStaleExecution :one
+UPDATE executions
+SET
+  status = 'cancelled',
+  message = 'Execution cancelled',
+  finished_at = NOW()
+WHERE id = @id AND status = 'running'
+RETURNING *;
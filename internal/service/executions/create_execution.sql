@@ -1,4 +1,18 @@
 -- name: ExecutionsServiceCreateExecution :one
-INSERT INTO executions (backup_id, status, message, path)
-VALUES (@backup_id, @status, @message, @path)
+INSERT INTO executions (
+  backup_id, status, message, path, jobs, kind, format, compression,
+  opt_clean, opt_if_exists, opt_create, opt_no_comments,
+  encryption_passphrase, run_id, attempt
+)
+VALUES (
+  @backup_id, @status, @message, @path, @jobs, @kind, @format, @compression,
+  @opt_clean, @opt_if_exists, @opt_create, @opt_no_comments,
+  (
+    CASE WHEN sqlc.arg('encrypted')::BOOLEAN
+    THEN (SELECT encryption_passphrase FROM backups WHERE id = @backup_id)
+    ELSE NULL
+    END
+  ),
+  @run_id, @attempt
+)
 RETURNING *;
@@ -1,27 +1,40 @@
 package executions
 
 import (
+	"context"
+	"sync"
+
 	"github.com/eduardolat/pgbackweb/internal/config"
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/integration"
+	"github.com/eduardolat/pgbackweb/internal/service/databases"
 	"github.com/eduardolat/pgbackweb/internal/service/webhooks"
+	"github.com/google/uuid"
 )
 
 type Service struct {
-	env             config.Env
-	dbgen           *dbgen.Queries
-	ints            *integration.Integration
-	webhooksService *webhooks.Service
+	env              config.Env
+	dbgen            *dbgen.Queries
+	ints             *integration.Integration
+	webhooksService  *webhooks.Service
+	databasesService *databases.Service
+
+	// runningMu guards running, the cancel functions of the executions that
+	// are running in this process
+	runningMu sync.Mutex
+	running   map[uuid.UUID]context.CancelFunc
 }
 
 func New(
 	env config.Env, dbgen *dbgen.Queries, ints *integration.Integration,
-	webhooksService *webhooks.Service,
+	webhooksService *webhooks.Service, databasesService *databases.Service,
 ) *Service {
 	return &Service{
-		env:             env,
-		dbgen:           dbgen,
-		ints:            ints,
-		webhooksService: webhooksService,
+		env:              env,
+		dbgen:            dbgen,
+		ints:             ints,
+		webhooksService:  webhooksService,
+		databasesService: databasesService,
+		running:          map[uuid.UUID]context.CancelFunc{},
 	}
 }
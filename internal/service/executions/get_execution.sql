@@ -2,7 +2,8 @@
 SELECT
   executions.*,
   databases.id AS database_id,
-  databases.pg_version AS database_pg_version
+  databases.pg_version AS database_pg_version,
+  backups.kind AS backup_kind
 FROM executions
 INNER JOIN backups ON backups.id = executions.backup_id
 INNER JOIN databases ON databases.id = backups.database_id
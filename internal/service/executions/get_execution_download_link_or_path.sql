@@ -1,6 +1,12 @@
 -- name: ExecutionsServiceGetDownloadLinkOrPathData :one
 SELECT
   executions.path AS path,
+  (
+    CASE WHEN executions.encryption_passphrase IS NOT NULL
+    THEN pgp_sym_decrypt(executions.encryption_passphrase, sqlc.arg('decryption_key')::TEXT)
+    ELSE ''
+    END
+  ) AS decrypted_encryption_passphrase,
   backups.is_local AS is_local,
   destinations.bucket_name AS bucket_name,
   destinations.region AS region,
@@ -0,0 +1,16 @@
+package executions
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+// GetLatestSuccessfulExecution returns the newest successful execution of the
+// given backup that still has its file.
+func (s *Service) GetLatestSuccessfulExecution(
+	ctx context.Context, backupID uuid.UUID,
+) (dbgen.Execution, error) {
+	return s.dbgen.ExecutionsServiceGetLatestSuccessfulExecution(ctx, backupID)
+}
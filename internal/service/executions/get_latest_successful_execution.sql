@@ -0,0 +1,7 @@
+-- name: ExecutionsServiceGetLatestSuccessfulExecution :one
+SELECT * FROM executions
+WHERE backup_id = @backup_id
+AND status = 'success'
+AND path IS NOT NULL
+ORDER BY started_at DESC
+LIMIT 1;
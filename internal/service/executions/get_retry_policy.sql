@@ -0,0 +1,7 @@
+-- name: ExecutionsServiceGetRetryPolicy :one
+SELECT
+  retry_max_attempts,
+  retry_initial_delay_seconds,
+  retry_backoff_factor
+FROM backups
+WHERE id = @backup_id;
@@ -0,0 +1,35 @@
+package executions
+
+import (
+	"context"
+	"io"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/google/uuid"
+)
+
+// InspectExecution returns the objects contained in the file of the given
+// execution, like schemas, tables with their approximate data size,
+// functions and extensions.
+//
+// The whole file is streamed from its storage, so it can take a while for
+// big backups.
+func (s *Service) InspectExecution(
+	ctx context.Context, executionID uuid.UUID,
+) ([]postgres.DumpObject, error) {
+	var objects []postgres.DumpObject
+	err := s.readExecutionDump(ctx, executionID, executionDumpReaders{
+		stream: func(
+			pgVersion postgres.PGVersion, r io.Reader,
+			dumpFormat postgres.DumpFormat, comp postgres.Compression,
+		) (err error) {
+			objects, err = s.ints.PGClient.InspectDump(ctx, pgVersion, r, dumpFormat, comp)
+			return err
+		},
+		zip: func(pgVersion postgres.PGVersion, r io.ReaderAt, size int64) (err error) {
+			objects, err = s.ints.PGClient.InspectDumpZip(ctx, pgVersion, r, size)
+			return err
+		},
+	})
+	return objects, err
+}
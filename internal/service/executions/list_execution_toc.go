@@ -0,0 +1,95 @@
+package executions
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/google/uuid"
+)
+
+// ListExecutionTOC returns the table of contents of the custom or directory
+// archive of the given execution, streamed from its storage.
+func (s *Service) ListExecutionTOC(
+	ctx context.Context, executionID uuid.UUID,
+) ([]postgres.TOCEntry, error) {
+	var entries []postgres.TOCEntry
+	err := s.readExecutionDump(ctx, executionID, executionDumpReaders{
+		stream: func(
+			pgVersion postgres.PGVersion, r io.Reader,
+			dumpFormat postgres.DumpFormat, comp postgres.Compression,
+		) (err error) {
+			entries, err = s.ints.PGClient.ListArchive(ctx, pgVersion, r, dumpFormat, comp)
+			return err
+		},
+		zip: func(pgVersion postgres.PGVersion, r io.ReaderAt, size int64) (err error) {
+			entries, err = s.ints.PGClient.ListArchiveZip(ctx, pgVersion, r, size)
+			return err
+		},
+	})
+	return entries, err
+}
+
+// executionDumpReaders read the dump of an execution, stream is used for the
+// artifacts that can be streamed and zip for legacy ZIP artifacts.
+type executionDumpReaders struct {
+	stream func(
+		pgVersion postgres.PGVersion, r io.Reader,
+		dumpFormat postgres.DumpFormat, comp postgres.Compression,
+	) error
+	zip func(pgVersion postgres.PGVersion, r io.ReaderAt, size int64) error
+}
+
+// readExecutionDump opens the file of a successful database or globals
+// execution from its storage and passes it to the matching reader.
+func (s *Service) readExecutionDump(
+	ctx context.Context, executionID uuid.UUID, readers executionDumpReaders,
+) error {
+	execution, err := s.GetExecution(ctx, executionID)
+	if err != nil {
+		return err
+	}
+
+	if execution.Status != "success" || !execution.Path.Valid {
+		return fmt.Errorf("backup execution must be successful")
+	}
+
+	if execution.Kind == "physical" {
+		return fmt.Errorf("physical backups can't be read, only downloaded")
+	}
+
+	pgVersion, err := s.ints.PGClient.ParseVersion(execution.DatabasePgVersion)
+	if err != nil {
+		return err
+	}
+
+	dumpFormat, err := s.ints.PGClient.ParseDumpFormat(execution.Format)
+	if err != nil {
+		return err
+	}
+
+	compression, err := s.ints.PGClient.ParseCompression(execution.Compression)
+	if err != nil {
+		return err
+	}
+
+	// Legacy ZIP files can't be streamed, they are read at random offsets
+	if compression == postgres.CompressionZip {
+		file, err := s.OpenExecutionFileAt(ctx, executionID)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return readers.zip(pgVersion, file, file.Size())
+	}
+
+	file, err := s.OpenDecryptedExecutionFile(ctx, executionID)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return readers.stream(pgVersion, file, dumpFormat, compression)
+}
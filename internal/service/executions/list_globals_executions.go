@@ -0,0 +1,15 @@
+package executions
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+)
+
+// ListGlobalsExecutions returns the latest successful executions of globals
+// and roles backups, they can be applied before restoring a database dump.
+func (s *Service) ListGlobalsExecutions(
+	ctx context.Context,
+) ([]dbgen.ExecutionsServiceListGlobalsExecutionsRow, error) {
+	return s.dbgen.ExecutionsServiceListGlobalsExecutions(ctx)
+}
@@ -0,0 +1,12 @@
+-- name: ExecutionsServiceListGlobalsExecutions :many
+SELECT
+  executions.*,
+  backups.name AS backup_name,
+  backups.kind AS backup_kind
+FROM executions
+INNER JOIN backups ON backups.id = executions.backup_id
+WHERE backups.kind IN ('globals', 'roles')
+AND executions.status = 'success'
+AND executions.path IS NOT NULL
+ORDER BY executions.started_at DESC
+LIMIT 50;
@@ -0,0 +1,130 @@
+package executions
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
+	"github.com/eduardolat/pgbackweb/internal/util/cryptoutil"
+	"github.com/google/uuid"
+)
+
+// EncryptedExtension is appended to the file name of encrypted artifacts.
+const EncryptedExtension = ".enc"
+
+// OpenExecutionFile returns a reader that streams the file associated with
+// the given execution from the local backups directory or S3, as it is
+// stored, even if it is encrypted.
+//
+// The caller must close the returned reader.
+func (s *Service) OpenExecutionFile(
+	ctx context.Context, executionID uuid.UUID,
+) (io.ReadCloser, error) {
+	data, err := s.getExecutionFileData(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.openExecutionFile(data)
+}
+
+// OpenDecryptedExecutionFile works like OpenExecutionFile but decrypts the
+// file on the fly if the execution is encrypted.
+//
+// The caller must close the returned reader.
+func (s *Service) OpenDecryptedExecutionFile(
+	ctx context.Context, executionID uuid.UUID,
+) (io.ReadCloser, error) {
+	data, err := s.getExecutionFileData(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.openExecutionFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.DecryptedEncryptionPassphrase == "" {
+		return file, nil
+	}
+
+	decrypted, err := cryptoutil.DecryptStream(
+		file, data.DecryptedEncryptionPassphrase,
+	)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return decryptedFile{Reader: decrypted, Closer: file}, nil
+}
+
+// decryptedFile reads the decrypted content and closes the underlying file.
+type decryptedFile struct {
+	io.Reader
+	io.Closer
+}
+
+func (s *Service) openExecutionFile(
+	data dbgen.ExecutionsServiceGetDownloadLinkOrPathDataRow,
+) (io.ReadCloser, error) {
+	if data.IsLocal {
+		return s.ints.StorageClient.LocalOpen(data.Path.String)
+	}
+
+	return s.ints.StorageClient.S3Download(
+		data.DecryptedAccessKey, data.DecryptedSecretKey, data.Region.String,
+		data.Endpoint.String, data.BucketName.String, data.Path.String,
+	)
+}
+
+// OpenExecutionFileAt returns a reader that can read the file associated
+// with the given execution at any offset, needed by legacy ZIP files.
+//
+// The caller must close the returned reader.
+func (s *Service) OpenExecutionFileAt(
+	ctx context.Context, executionID uuid.UUID,
+) (storage.ReaderAt, error) {
+	data, err := s.getExecutionFileData(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only legacy ZIP files are read at random offsets, they are never
+	// encrypted
+	if data.DecryptedEncryptionPassphrase != "" {
+		return nil, fmt.Errorf("encrypted files can't be read at random offsets")
+	}
+
+	if data.IsLocal {
+		return s.ints.StorageClient.LocalOpenAt(data.Path.String)
+	}
+
+	return s.ints.StorageClient.S3OpenAt(
+		data.DecryptedAccessKey, data.DecryptedSecretKey, data.Region.String,
+		data.Endpoint.String, data.BucketName.String, data.Path.String,
+	)
+}
+
+func (s *Service) getExecutionFileData(
+	ctx context.Context, executionID uuid.UUID,
+) (dbgen.ExecutionsServiceGetDownloadLinkOrPathDataRow, error) {
+	data, err := s.dbgen.ExecutionsServiceGetDownloadLinkOrPathData(
+		ctx, dbgen.ExecutionsServiceGetDownloadLinkOrPathDataParams{
+			ExecutionID:   executionID,
+			DecryptionKey: s.env.PBW_ENCRYPTION_KEY,
+		},
+	)
+	if err != nil {
+		return data, err
+	}
+
+	if !data.Path.Valid {
+		return data, fmt.Errorf("execution has no file associated")
+	}
+
+	return data, nil
+}
@@ -2,33 +2,182 @@ package executions
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/util/cryptoutil"
 	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
 	"github.com/google/uuid"
 )
 
+// maxRetryDelay is the longest wait between two attempts of a run, whatever
+// the backoff factor is.
+const maxRetryDelay = 24 * time.Hour
+
 // RunExecution runs a backup execution
+//
+// The execution is cancelled by CancelExecution or when the context is done,
+// the backup process is killed and the execution is marked as cancelled.
+//
+// The execution fails when it exceeds the timeout of the backup, or the
+// PBW_BACKUP_TIMEOUT default if the backup has no timeout.
+//
+// Failed executions are retried following the retry policy of the backup,
+// every attempt is a new execution with the run ID of the first one and the
+// failure webhooks only run after the last attempt. The wait for the next
+// attempt is stopped by cancelling the failed execution.
 func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
+	policy, err := s.dbgen.ExecutionsServiceGetRetryPolicy(ctx, backupID)
+	if err != nil {
+		logger.Error("error running backup", logger.KV{
+			"backup_id": backupID.String(),
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	attempt := &executionAttempt{RunID: uuid.New()}
+	delay := time.Duration(policy.RetryInitialDelaySeconds) * time.Second
+
+	for attempt.Number = 1; ; attempt.Number++ {
+		attempt.IsLast = attempt.Number >= policy.RetryMaxAttempts
+		attempt.Status = ""
+
+		err = s.runExecutionAttempt(ctx, backupID, attempt)
+		if attempt.Status != "failed" || attempt.IsLast {
+			return err
+		}
+
+		logger.Info("retrying failed backup", logger.KV{
+			"backup_id": backupID.String(),
+			"run_id":    attempt.RunID.String(),
+			"attempt":   attempt.Number + 1,
+			"delay":     delay.String(),
+		})
+
+		if err := s.waitRetry(ctx, attempt.ExecutionID, delay); err != nil {
+			return err
+		}
+
+		delay = min(
+			time.Duration(float64(delay)*policy.RetryBackoffFactor), maxRetryDelay,
+		)
+	}
+}
+
+// waitRetry waits for the delay before the next attempt, it is tracked as
+// the failed execution so CancelExecution can stop it.
+func (s *Service) waitRetry(
+	ctx context.Context, executionID uuid.UUID, delay time.Duration,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer s.trackRunning(executionID, cancel)()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// executionAttempt is an attempt of a scheduled run of a backup.
+type executionAttempt struct {
+	RunID  uuid.UUID
+	Number int32
+	IsLast bool
+
+	// ExecutionID is the execution created by the attempt.
+	ExecutionID uuid.UUID
+
+	// Status is the status the execution of the attempt finished with, it is
+	// empty if the execution couldn't be created or updated.
+	Status string
+}
+
+// runExecutionAttempt runs the execution of an attempt and stores its final
+// status in the attempt.
+func (s *Service) runExecutionAttempt(
+	ctx context.Context, backupID uuid.UUID, attempt *executionAttempt,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The execution is updated even if it was cancelled
+	updateCtx := context.WithoutCancel(ctx)
+
+	// The output of the hooks is stored when the execution finishes
+	hooksOutput := &strings.Builder{}
+
+	// The post-backup hook is set once the pre-backup hook runs, it runs when
+	// the execution finishes even if it failed, so whatever the pre-backup
+	// hook paused is resumed
+	var postHook func() error
+
+	// The timeout is set once the backup data is read
+	var timeout time.Duration
+
 	updateExec := func(params dbgen.ExecutionsServiceUpdateExecutionParams) error {
+		// The partial file was already removed by the storage client
+		if ctx.Err() != nil && params.Status.String == "failed" {
+			params.Path = sql.NullString{}
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				params.Message = sql.NullString{
+					Valid: true, String: fmt.Sprintf("Execution timed out after %s", timeout),
+				}
+			} else {
+				params.Status = sql.NullString{Valid: true, String: "cancelled"}
+				params.Message = sql.NullString{Valid: true, String: "Execution cancelled"}
+			}
+		}
+
+		if postHook != nil {
+			err := postHook()
+			postHook = nil
+			if err != nil && params.Status.String == "success" {
+				params.Status = sql.NullString{Valid: true, String: "failed"}
+				params.Message = sql.NullString{Valid: true, String: err.Error()}
+			}
+		}
+
+		if hooksOutput.Len() > 0 {
+			params.HooksOutput = sql.NullString{
+				Valid: true, String: hooksOutput.String(),
+			}
+		}
+
 		if params.Status.String == "success" {
 			s.webhooksService.RunExecutionSuccess(backupID)
 		}
 
-		if params.Status.String == "failed" {
+		// The failure is only notified when there are no attempts left
+		if params.Status.String == "failed" && attempt.IsLast {
 			s.webhooksService.RunExecutionFailed(backupID)
 		}
 
 		_, err := s.dbgen.ExecutionsServiceUpdateExecution(
-			ctx, params,
+			updateCtx, params,
 		)
-		return err
+		if err != nil {
+			return err
+		}
+
+		attempt.Status = params.Status.String
+		return nil
 	}
 
 	logError := func(err error) {
@@ -49,14 +198,65 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 		return err
 	}
 
+	timeout = s.env.PBW_BACKUP_TIMEOUT
+	if back.BackupTimeoutMinutes > 0 {
+		timeout = time.Duration(back.BackupTimeoutMinutes) * time.Minute
+	}
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+	}
+
+	// Physical backups are always a ZIP file with the base backup and the
+	// recovery instructions
+	dumpFormat := postgres.FormatPlain
+	compression := postgres.CompressionZip
+	dumpOptions := postgres.DumpOptions{}
+	var parseErr error
+	if back.BackupKind != "physical" {
+		f, formatErr := s.ints.PGClient.ParseDumpFormat(back.BackupOptFormat)
+		c, compressionErr := s.ints.PGClient.ParseCompression(back.BackupOptCompression)
+		o, optionsErr := s.ints.PGClient.ParseDumpOptions(back.BackupOptDumpOptions)
+		parseErr = errors.Join(formatErr, compressionErr, optionsErr)
+		if parseErr == nil {
+			dumpFormat, compression, dumpOptions = f, c, o
+		}
+	}
+
+	encrypted := back.DecryptedBackupEncryptionPassphrase != ""
+
 	ex, err := s.CreateExecution(ctx, dbgen.ExecutionsServiceCreateExecutionParams{
-		BackupID: backupID,
-		Status:   "running",
+		BackupID:      backupID,
+		Status:        "running",
+		Jobs:          sql.NullInt16{Valid: true, Int16: back.BackupOptJobs},
+		Kind:          back.BackupKind,
+		Format:        dumpFormat.Value.Key,
+		Compression:   compression.Value.Key,
+		OptClean:      back.BackupOptClean,
+		OptIfExists:   back.BackupOptIfExists,
+		OptCreate:     back.BackupOptCreate,
+		OptNoComments: back.BackupOptNoComments,
+		Encrypted:     encrypted,
+		RunID:         attempt.RunID,
+		Attempt:       attempt.Number,
 	})
 	if err != nil {
 		logError(err)
 		return err
 	}
+	attempt.ExecutionID = ex.ID
+	defer s.trackRunning(ex.ID, cancel)()
+
+	if parseErr != nil {
+		logError(parseErr)
+		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:         ex.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: parseErr.Error()},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
 
 	if !back.BackupIsLocal {
 		err = s.ints.StorageClient.S3Test(
@@ -75,7 +275,9 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 		}
 	}
 
-	pgVersion, err := s.ints.PGClient.ParseVersion(back.DatabasePgVersion)
+	// The connection is kept open until the artifact is uploaded, the dump is
+	// streamed while it is read
+	conn, err := s.databasesService.OpenConnection(ctx, back.DatabaseID)
 	if err != nil {
 		logError(err)
 		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
@@ -85,8 +287,9 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
 		})
 	}
+	defer conn.Close()
 
-	err = s.ints.PGClient.Test(pgVersion, back.DecryptedDatabaseConnectionString)
+	pgVersion, err := s.ints.PGClient.Test(ctx, conn.ConnString)
 	if err != nil {
 		logError(err)
 		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
@@ -97,22 +300,129 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 		})
 	}
 
-	dumpReader := s.ints.PGClient.DumpZip(
-		pgVersion, back.DecryptedDatabaseConnectionString, postgres.DumpParams{
-			DataOnly:   back.BackupOptDataOnly,
-			SchemaOnly: back.BackupOptSchemaOnly,
-			Clean:      back.BackupOptClean,
-			IfExists:   back.BackupOptIfExists,
-			Create:     back.BackupOptCreate,
-			NoComments: back.BackupOptNoComments,
-		},
+	err = s.databasesService.SyncPgVersion(
+		ctx, back.DatabaseID, back.DatabasePgVersion, pgVersion,
 	)
+	if err != nil {
+		logError(err)
+		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:         ex.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: err.Error()},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
+	hookTimeout := time.Duration(back.BackupHookTimeoutSeconds) * time.Second
+	abortOnHookFailure := back.BackupHookFailurePolicy == "abort"
+
+	preHookErr := s.runHook(
+		ctx, "pre-backup", pgVersion, conn.ConnString, postgres.HookParams{
+			SQL:     back.BackupPreHookSql,
+			Command: back.BackupPreHookCommand,
+			Timeout: hookTimeout,
+		}, hooksOutput,
+	)
+	postHook = func() error {
+		// The post-backup hook also runs when the execution is cancelled
+		err := s.runHook(
+			updateCtx, "post-backup", pgVersion, conn.ConnString,
+			postgres.HookParams{
+				SQL:     back.BackupPostHookSql,
+				Command: back.BackupPostHookCommand,
+				Timeout: hookTimeout,
+			}, hooksOutput,
+		)
+		if err != nil {
+			logError(err)
+		}
+		if !abortOnHookFailure {
+			return nil
+		}
+		return err
+	}
+	if preHookErr != nil {
+		logError(preHookErr)
+		if abortOnHookFailure {
+			return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+				ID:         ex.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: preHookErr.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			})
+		}
+	}
+
+	var dumpReader io.Reader
+	switch back.BackupKind {
+	case "physical":
+		dumpReader = s.ints.PGClient.BaseBackupZip(ctx, pgVersion, conn.ConnString)
+	case "globals", "roles":
+		dumpReader = s.ints.PGClient.DumpGlobals(
+			ctx, pgVersion, conn.ConnString, postgres.DumpGlobalsParams{
+				RolesOnly: back.BackupKind == "roles",
+			},
+		)
+	default:
+		dumpReader = s.ints.PGClient.Dump(
+			ctx, pgVersion, conn.ConnString, postgres.DumpParams{
+				DataOnly:   back.BackupOptDataOnly,
+				SchemaOnly: back.BackupOptSchemaOnly,
+				Clean:      back.BackupOptClean,
+				IfExists:   back.BackupOptIfExists,
+				Create:     back.BackupOptCreate,
+				NoComments: back.BackupOptNoComments,
+				Format:     dumpFormat,
+				Jobs:       int(back.BackupOptJobs),
+
+				Schemas:          back.BackupOptSchemas,
+				ExcludeSchemas:   back.BackupOptExcludeSchemas,
+				Tables:           back.BackupOptTables,
+				ExcludeTables:    back.BackupOptExcludeTables,
+				ExcludeTableData: back.BackupOptExcludeTableData,
+
+				Options: dumpOptions,
+			},
+		)
+	}
+	if compression != postgres.CompressionZip {
+		dumpReader = s.ints.PGClient.Compress(
+			dumpReader, compression, int(back.BackupOptCompressionLevel),
+		)
+	}
+
+	// The artifact is encrypted after compressing it, encrypted data can't be
+	// compressed
+	if encrypted {
+		dumpReader, err = cryptoutil.EncryptStream(
+			dumpReader, back.DecryptedBackupEncryptionPassphrase,
+		)
+		if err != nil {
+			logError(err)
+			return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+				ID:         ex.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: err.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			})
+		}
+	}
+
+	// The checksum is computed while the artifact is uploaded
+	hash := sha256.New()
+	dumpReader = io.TeeReader(dumpReader, hash)
+
+	extension := postgres.ArtifactExtension(dumpFormat, compression)
+	if encrypted {
+		extension += EncryptedExtension
+	}
 
 	date := time.Now().Format(timeutil.LayoutSlashYYYYMMDD)
 	file := fmt.Sprintf(
-		"dump-%s-%s.zip",
+		"dump-%s-%s%s",
 		time.Now().Format(timeutil.LayoutYYYYMMDDHHMMSS),
 		uuid.NewString(),
+		extension,
 	)
 	path := strutil.CreatePath(false, back.BackupDestDir, date, file)
 	fileSize := int64(0)
@@ -149,6 +459,18 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 		}
 	}
 
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if err := s.uploadChecksumFile(back, path, checksum); err != nil {
+		logError(err)
+		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:         ex.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: err.Error()},
+			Path:       sql.NullString{Valid: true, String: path},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
 	logger.Info("backup created successfully", logger.KV{
 		"backup_id":    backupID.String(),
 		"execution_id": ex.ID.String(),
@@ -160,5 +482,6 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 		Path:       sql.NullString{Valid: true, String: path},
 		FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
 		FileSize:   sql.NullInt64{Valid: true, Int64: fileSize},
+		Checksum:   sql.NullString{Valid: true, String: checksum},
 	})
 }
@@ -1,6 +1,7 @@
 -- name: ExecutionsServiceGetBackupData :one
 SELECT
   backups.is_active as backup_is_active,
+  backups.kind as backup_kind,
   backups.is_local as backup_is_local,
   backups.dest_dir as backup_dest_dir,
   backups.opt_data_only as backup_opt_data_only,
@@ -9,8 +10,32 @@ SELECT
   backups.opt_if_exists as backup_opt_if_exists,
   backups.opt_create as backup_opt_create,	
   backups.opt_no_comments as backup_opt_no_comments,
+  backups.opt_format as backup_opt_format,
+  backups.opt_jobs as backup_opt_jobs,
+  backups.opt_schemas as backup_opt_schemas,
+  backups.opt_exclude_schemas as backup_opt_exclude_schemas,
+  backups.opt_tables as backup_opt_tables,
+  backups.opt_exclude_tables as backup_opt_exclude_tables,
+  backups.opt_exclude_table_data as backup_opt_exclude_table_data,
+  backups.opt_compression as backup_opt_compression,
+  backups.opt_compression_level as backup_opt_compression_level,
+  backups.opt_dump_options as backup_opt_dump_options,
+  backups.pre_hook_sql as backup_pre_hook_sql,
+  backups.pre_hook_command as backup_pre_hook_command,
+  backups.post_hook_sql as backup_post_hook_sql,
+  backups.post_hook_command as backup_post_hook_command,
+  backups.hook_timeout_seconds as backup_hook_timeout_seconds,
+  backups.hook_failure_policy as backup_hook_failure_policy,
+  backups.timeout_minutes as backup_timeout_minutes,
+  (
+    CASE WHEN backups.encryption_passphrase IS NOT NULL
+    THEN pgp_sym_decrypt(backups.encryption_passphrase, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_backup_encryption_passphrase,
 
   pgp_sym_decrypt(databases.connection_string, @encryption_key) AS decrypted_database_connection_string,
+  databases.id as database_id,
   databases.pg_version as database_pg_version,
 
   destinations.bucket_name as destination_bucket_name,
@@ -0,0 +1,47 @@
+package executions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+)
+
+// hookOutputLimit is the maximum number of bytes of the output of a hook
+// that are stored in the execution, only the end of longer outputs is kept.
+const hookOutputLimit = 64 * 1024
+
+// runHook runs a hook of a backup, if it is enabled, and appends its output
+// to the hooks output of the execution.
+func (s *Service) runHook(
+	ctx context.Context, name string, version postgres.PGVersion,
+	connString string, params postgres.HookParams, output *strings.Builder,
+) error {
+	if !params.IsEnabled() {
+		return nil
+	}
+
+	out, err := s.ints.PGClient.RunHook(ctx, version, connString, params)
+	if len(out) > hookOutputLimit {
+		// Skip the continuation bytes of a rune cut by the limit
+		tail := out[len(out)-hookOutputLimit:]
+		for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+			tail = tail[1:]
+		}
+		out = "[output truncated]\n" + tail
+	}
+	out = strings.ToValidUTF8(out, string(utf8.RuneError))
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+
+	fmt.Fprintf(output, "==> %s hook\n%s", name, out)
+	if err != nil {
+		fmt.Fprintf(output, "%s hook failed: %s\n", name, err)
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package executions
+
+import (
+	"context"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/logger"
+)
+
+// integrityScanInterval is the time between two integrity checks of the same
+// execution, every check reads the whole file from its storage.
+const integrityScanInterval = 7 * 24 * time.Hour
+
+// ScanIntegrity verifies the checksum of the successful executions that were
+// not checked in the last integrityScanInterval, flagging the corrupted and
+// missing files.
+func (s *Service) ScanIntegrity() {
+	ctx := context.Background()
+
+	executionIDs, err := s.dbgen.ExecutionsServiceGetExecutionsToScan(
+		ctx, time.Now().Add(-integrityScanInterval),
+	)
+	if err != nil {
+		logger.Error("error scanning executions integrity", logger.KV{"error": err})
+		return
+	}
+
+	failed := 0
+	for _, executionID := range executionIDs {
+		if err := s.VerifyExecutionChecksum(ctx, executionID); err != nil {
+			failed++
+			logger.Error(
+				"error verifying execution integrity",
+				logger.KV{"id": executionID.String(), "error": err},
+			)
+		}
+	}
+
+	logger.Info("executions integrity scanned", logger.KV{
+		"scanned": len(executionIDs),
+		"failed":  failed,
+	})
+}
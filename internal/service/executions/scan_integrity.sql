@@ -0,0 +1,9 @@
+-- name: ExecutionsServiceGetExecutionsToScan :many
+SELECT id FROM executions
+WHERE status = 'success'
+AND path IS NOT NULL
+AND (
+  integrity_checked_at IS NULL
+  OR integrity_checked_at < sqlc.arg('checked_before')::TIMESTAMPTZ
+)
+ORDER BY integrity_checked_at ASC NULLS FIRST;
@@ -25,21 +25,32 @@ func (s *Service) SoftDeleteExecution(
 		return err
 	}
 
-	if execution.ExecutionPath.Valid && !execution.BackupIsLocal {
-		err := s.ints.StorageClient.S3Delete(
-			execution.DecryptedDestinationAccessKey, execution.DecryptedDestinationSecretKey,
-			execution.DestinationRegion.String, execution.DestinationEndpoint.String,
-			execution.DestinationBucketName.String, execution.ExecutionPath.String,
-		)
-		if err != nil {
-			return err
-		}
+	paths := []string{}
+	if execution.ExecutionPath.Valid {
+		paths = append(paths, execution.ExecutionPath.String)
+	}
+	// Executions created before checksums were recorded have no checksum file
+	if execution.ExecutionPath.Valid && execution.ExecutionChecksum.Valid {
+		paths = append(paths, checksumPath(execution.ExecutionPath.String))
 	}
 
-	if execution.ExecutionPath.Valid && execution.BackupIsLocal {
-		err := s.ints.StorageClient.LocalDelete(execution.ExecutionPath.String)
-		if err != nil {
-			return err
+	for _, path := range paths {
+		if !execution.BackupIsLocal {
+			err := s.ints.StorageClient.S3Delete(
+				execution.DecryptedDestinationAccessKey, execution.DecryptedDestinationSecretKey,
+				execution.DestinationRegion.String, execution.DestinationEndpoint.String,
+				execution.DestinationBucketName.String, path,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		if execution.BackupIsLocal {
+			err := s.ints.StorageClient.LocalDelete(path)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
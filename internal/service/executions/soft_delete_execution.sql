@@ -2,6 +2,7 @@
 SELECT
   executions.id as execution_id,
   executions.path as execution_path,
+  executions.checksum as execution_checksum,
 
   backups.id as backup_id,
   backups.is_local as backup_is_local,
@@ -6,6 +6,13 @@ SET
   path = COALESCE(sqlc.narg('path'), path),
   finished_at = COALESCE(sqlc.narg('finished_at'), finished_at),
   deleted_at = COALESCE(sqlc.narg('deleted_at'), deleted_at),
-  file_size = COALESCE(sqlc.narg('file_size'), file_size)
+  file_size = COALESCE(sqlc.narg('file_size'), file_size),
+  verification_status = COALESCE(sqlc.narg('verification_status'), verification_status),
+  verification_message = COALESCE(sqlc.narg('verification_message'), verification_message),
+  verified_at = COALESCE(sqlc.narg('verified_at'), verified_at),
+  checksum = COALESCE(sqlc.narg('checksum'), checksum),
+  integrity_status = COALESCE(sqlc.narg('integrity_status'), integrity_status),
+  integrity_checked_at = COALESCE(sqlc.narg('integrity_checked_at'), integrity_checked_at),
+  hooks_output = COALESCE(sqlc.narg('hooks_output'), hooks_output)
 WHERE id = @id
 RETURNING *;
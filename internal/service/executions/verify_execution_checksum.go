@@ -0,0 +1,112 @@
+package executions
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
+	"github.com/google/uuid"
+)
+
+// checksumPath returns the path of the sidecar file that stores the checksum
+// of the artifact at the given path.
+func checksumPath(artifactPath string) string {
+	return artifactPath + ".sha256"
+}
+
+// uploadChecksumFile stores the checksum of the artifact next to it, in the
+// format of sha256sum so it can be verified with sha256sum --check.
+func (s *Service) uploadChecksumFile(
+	back dbgen.ExecutionsServiceGetBackupDataRow,
+	artifactPath string,
+	checksum string,
+) error {
+	content := strings.NewReader(
+		fmt.Sprintf("%s  %s\n", checksum, path.Base(artifactPath)),
+	)
+
+	if back.BackupIsLocal {
+		_, err := s.ints.StorageClient.LocalUpload(checksumPath(artifactPath), content)
+		return err
+	}
+
+	_, err := s.ints.StorageClient.S3Upload(
+		back.DecryptedDestinationAccessKey, back.DecryptedDestinationSecretKey,
+		back.DestinationRegion.String, back.DestinationEndpoint.String,
+		back.DestinationBucketName.String, checksumPath(artifactPath), content,
+	)
+	return err
+}
+
+// VerifyExecutionChecksum reads the file of the execution from its storage
+// and compares its SHA-256 with the checksum recorded when it was created.
+// The outcome is stored as the integrity status of the execution.
+//
+// Executions created before checksums were recorded are only checked to
+// exist.
+func (s *Service) VerifyExecutionChecksum(
+	ctx context.Context, executionID uuid.UUID,
+) error {
+	execution, err := s.GetExecution(ctx, executionID)
+	if err != nil {
+		return err
+	}
+
+	status, err := s.checkIntegrity(ctx, executionID, execution.Checksum)
+	if status == "" {
+		return err
+	}
+
+	_, updateErr := s.dbgen.ExecutionsServiceUpdateExecution(
+		ctx, dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:                 executionID,
+			IntegrityStatus:    sql.NullString{Valid: true, String: status},
+			IntegrityCheckedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		},
+	)
+	return errors.Join(err, updateErr)
+}
+
+// checkIntegrity returns the integrity status of the file of the execution,
+// ok, corrupted or missing, with the error that explains it. The status is
+// empty if the file could not be checked.
+func (s *Service) checkIntegrity(
+	ctx context.Context, executionID uuid.UUID, checksum sql.NullString,
+) (string, error) {
+	file, err := s.OpenExecutionFile(ctx, executionID)
+	if storage.IsNotExist(err) {
+		return "missing", fmt.Errorf("backup file not found: %w", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if !checksum.Valid {
+		return "ok", nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("error reading backup file: %w", err)
+	}
+
+	actual := hex.EncodeToString(hash.Sum(nil))
+	if actual != checksum.String {
+		return "corrupted", fmt.Errorf(
+			"backup file is corrupted: expected SHA-256 %s, got %s",
+			checksum.String, actual,
+		)
+	}
+
+	return "ok", nil
+}
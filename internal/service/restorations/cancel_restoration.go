@@ -0,0 +1,49 @@
+package restorations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// CancelRestoration cancels a running restoration. psql or pg_restore is
+// killed and the restoration is marked as cancelled by RunRestoration.
+//
+// Restorations left running by a previous process, for example after a
+// restart, are marked as cancelled right away.
+func (s *Service) CancelRestoration(
+	ctx context.Context, restorationID uuid.UUID,
+) error {
+	s.runningMu.Lock()
+	cancel, ok := s.running[restorationID]
+	s.runningMu.Unlock()
+	if ok {
+		cancel()
+		return nil
+	}
+
+	_, err := s.dbgen.RestorationsServiceCancelStaleRestoration(ctx, restorationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("restoration is not running")
+	}
+	return err
+}
+
+// trackRunning registers the cancel function of a running restoration, the
+// returned function must be called when the restoration finishes.
+func (s *Service) trackRunning(
+	restorationID uuid.UUID, cancel context.CancelFunc,
+) func() {
+	s.runningMu.Lock()
+	s.running[restorationID] = cancel
+	s.runningMu.Unlock()
+
+	return func() {
+		s.runningMu.Lock()
+		delete(s.running, restorationID)
+		s.runningMu.Unlock()
+	}
+}
@@ -0,0 +1,8 @@
+-- name: RestorationsServiceCancelStaleRestoration :one
+UPDATE restorations
+SET
+  status = 'cancelled',
+  message = 'Restoration cancelled',
+  finished_at = NOW()
+WHERE id = @id AND status = 'running'
+RETURNING *;
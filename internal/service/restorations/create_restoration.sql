@@ -1,4 +1,8 @@
 -- name: RestorationsServiceCreateRestoration :one
-INSERT INTO restorations (execution_id, database_id, status, message)
-VALUES (@execution_id, @database_id, @status, @message)
+INSERT INTO restorations (
+  execution_id, database_id, status, message, globals_execution_id
+)
+VALUES (
+  @execution_id, @database_id, @status, @message, @globals_execution_id
+)
 RETURNING *;
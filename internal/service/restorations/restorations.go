@@ -1,11 +1,16 @@
 package restorations
 
 import (
+	"context"
+	"sync"
+
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/integration"
 	"github.com/eduardolat/pgbackweb/internal/service/databases"
 	"github.com/eduardolat/pgbackweb/internal/service/destinations"
 	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/webhooks"
+	"github.com/google/uuid"
 )
 
 type Service struct {
@@ -14,12 +19,18 @@ type Service struct {
 	executionsService   *executions.Service
 	databasesService    *databases.Service
 	destinationsService *destinations.Service
+	webhooksService     *webhooks.Service
+
+	// runningMu guards running, the cancel functions of the restorations that
+	// are running in this process
+	runningMu sync.Mutex
+	running   map[uuid.UUID]context.CancelFunc
 }
 
 func New(
 	dbgen *dbgen.Queries, ints *integration.Integration,
 	executionsService *executions.Service, databasesService *databases.Service,
-	destinationsService *destinations.Service,
+	destinationsService *destinations.Service, webhooksService *webhooks.Service,
 ) *Service {
 	return &Service{
 		dbgen:               dbgen,
@@ -27,5 +38,7 @@ func New(
 		executionsService:   executionsService,
 		databasesService:    databasesService,
 		destinationsService: destinationsService,
+		webhooksService:     webhooksService,
+		running:             map[uuid.UUID]context.CancelFunc{},
 	}
 }
@@ -7,20 +7,84 @@ import (
 	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/databases"
 	"github.com/google/uuid"
 )
 
+// NewDatabaseParams contains the parameters to restore into a new database
+// created on demand.
+type NewDatabaseParams struct {
+	postgres.CreateDatabaseParams
+
+	// Register adds the new database to the databases table after a
+	// successful restoration.
+	Register bool
+}
+
+// SelectionParams contains the parameters to restore only part of a custom
+// or directory archive.
+type SelectionParams struct {
+	// Schemas restores every object of these schemas.
+	Schemas []string
+
+	// Tables restores these tables, written as schema.table or table to match
+	// it in the public schema, with their data, constraints and triggers.
+	Tables []string
+
+	// DataOnly restores only the data of the selected objects.
+	DataOnly bool
+
+	// SchemaOnly restores only the definitions of the selected objects.
+	SchemaOnly bool
+}
+
+// IsSelective returns true if only part of the archive must be restored.
+func (p SelectionParams) IsSelective() bool {
+	return len(p.Schemas) > 0 || len(p.Tables) > 0 || p.DataOnly || p.SchemaOnly
+}
+
 // RunRestoration runs a backup restoration
+//
+// If globalsExecutionID is valid, the globals or roles backup execution is
+// applied before restoring the database dump.
+//
+// If newDatabase has a name, the database or connection string is used as a
+// maintenance connection to create the new database and the backup is
+// restored into it.
+//
+// If selection is selective, only the picked schemas and tables of the
+// archive are restored, or only their data or definitions.
+//
+// The restoration is cancelled by CancelRestoration or when the context is
+// done, psql or pg_restore is killed and the restoration is marked as
+// cancelled.
 func (s *Service) RunRestoration(
 	ctx context.Context,
 	executionID uuid.UUID,
 	databaseID uuid.NullUUID,
 	connString string,
+	globalsExecutionID uuid.NullUUID,
+	newDatabase NewDatabaseParams,
+	selection SelectionParams,
 ) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The restoration is updated even if it was cancelled
+	updateCtx := context.WithoutCancel(ctx)
+
 	updateRes := func(params dbgen.RestorationsServiceUpdateRestorationParams) error {
+		if ctx.Err() != nil && params.Status.String == "failed" {
+			params.Status = sql.NullString{Valid: true, String: "cancelled"}
+			params.Message = sql.NullString{
+				Valid: true, String: "Restoration cancelled",
+			}
+		}
+
 		_, err := s.dbgen.RestorationsServiceUpdateRestoration(
-			ctx, params,
+			updateCtx, params,
 		)
 		return err
 	}
@@ -38,14 +102,16 @@ func (s *Service) RunRestoration(
 	}
 
 	res, err := s.CreateRestoration(ctx, dbgen.RestorationsServiceCreateRestorationParams{
-		ExecutionID: executionID,
-		DatabaseID:  databaseID,
-		Status:      "running",
+		ExecutionID:        executionID,
+		DatabaseID:         databaseID,
+		Status:             "running",
+		GlobalsExecutionID: globalsExecutionID,
 	})
 	if err != nil {
 		logError(err)
 		return err
 	}
+	defer s.trackRunning(res.ID, cancel)()
 
 	if !databaseID.Valid && connString == "" {
 		err := fmt.Errorf("database_id or connection_string must be provided")
@@ -69,6 +135,20 @@ func (s *Service) RunRestoration(
 		})
 	}
 
+	if execution.Kind == "physical" {
+		err := fmt.Errorf(
+			"physical backups can't be restored automatically, download the " +
+				"execution and follow the recovery instructions",
+		)
+		logError(err)
+		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+			ID:         res.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: err.Error()},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
 	if execution.Status != "success" || !execution.Path.Valid {
 		err := fmt.Errorf("backup execution must be successful")
 		logError(err)
@@ -80,6 +160,7 @@ func (s *Service) RunRestoration(
 		})
 	}
 
+	var connParams postgres.ConnectionParams
 	if databaseID.Valid {
 		db, err := s.databasesService.GetDatabase(ctx, databaseID.UUID)
 		if err != nil {
@@ -92,7 +173,21 @@ func (s *Service) RunRestoration(
 			})
 		}
 		connString = db.DecryptedConnectionString
+		connParams = databases.ConnectionParams(db)
+	}
+
+	conn, err := s.ints.PGClient.OpenConnection(connString, connParams)
+	if err != nil {
+		logError(err)
+		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+			ID:         res.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: err.Error()},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
 	}
+	defer conn.Close()
+	restoreConnString := conn.ConnString
 
 	pgVersion, err := s.ints.PGClient.ParseVersion(execution.DatabasePgVersion)
 	if err != nil {
@@ -105,7 +200,7 @@ func (s *Service) RunRestoration(
 		})
 	}
 
-	err = s.ints.PGClient.Test(pgVersion, connString)
+	targetVersion, err := s.ints.PGClient.Test(ctx, restoreConnString)
 	if err != nil {
 		logError(err)
 		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
@@ -116,9 +211,44 @@ func (s *Service) RunRestoration(
 		})
 	}
 
-	isLocal, zipURLOrPath, err := s.executionsService.GetExecutionDownloadLinkOrPath(
-		ctx, executionID,
-	)
+	// The binaries must be at least as new as the dump and the target server
+	if targetVersion.IsNewerThan(pgVersion) {
+		pgVersion = targetVersion
+	}
+
+	if globalsExecutionID.Valid {
+		err = s.applyGlobals(
+			ctx, pgVersion, restoreConnString, globalsExecutionID.UUID,
+		)
+		if err != nil {
+			logError(err)
+			return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+				ID:         res.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: err.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			})
+		}
+	}
+
+	// Globals are applied first so the owner of the new database can be one
+	// of the restored roles
+	if newDatabase.Name != "" {
+		restoreConnString, err = s.createNewDatabase(
+			ctx, execution, pgVersion, restoreConnString, newDatabase,
+		)
+		if err != nil {
+			logError(err)
+			return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+				ID:         res.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: err.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			})
+		}
+	}
+
+	restoreParams, err := s.restoreParams(ctx, execution, selection)
 	if err != nil {
 		logError(err)
 		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
@@ -129,8 +259,8 @@ func (s *Service) RunRestoration(
 		})
 	}
 
-	err = s.ints.PGClient.RestoreZip(
-		pgVersion, connString, isLocal, zipURLOrPath,
+	err = s.restoreExecution(
+		ctx, execution, pgVersion, restoreConnString, restoreParams,
 	)
 	if err != nil {
 		logError(err)
@@ -142,6 +272,18 @@ func (s *Service) RunRestoration(
 		})
 	}
 
+	message := "Backup restored successfully"
+	if newDatabase.Name != "" && newDatabase.Register {
+		err := s.registerNewDatabase(ctx, connString, connParams, newDatabase)
+		if err != nil {
+			logError(err)
+			message = fmt.Sprintf(
+				"Backup restored successfully but the new database could not be "+
+					"registered: %s", err,
+			)
+		}
+	}
+
 	logger.Info("backup restored successfully", logger.KV{
 		"restoration_id": res.ID.String(),
 		"execution_id":   executionID.String(),
@@ -149,7 +291,215 @@ func (s *Service) RunRestoration(
 	return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
 		ID:         res.ID,
 		Status:     sql.NullString{Valid: true, String: "success"},
-		Message:    sql.NullString{Valid: true, String: "Backup restored successfully"},
+		Message:    sql.NullString{Valid: true, String: message},
 		FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
 	})
 }
+
+// restoreParams returns the pg_restore parameters of the execution, limited
+// to the selected objects of the archive.
+func (s *Service) restoreParams(
+	ctx context.Context,
+	execution dbgen.ExecutionsServiceGetExecutionRow,
+	selection SelectionParams,
+) (postgres.RestoreParams, error) {
+	params := postgres.RestoreParams{
+		Clean:      execution.OptClean,
+		IfExists:   execution.OptIfExists,
+		Create:     execution.OptCreate,
+		NoComments: execution.OptNoComments,
+		Jobs:       int(execution.Jobs.Int16),
+	}
+
+	if !selection.IsSelective() {
+		return params, nil
+	}
+
+	if execution.Format == postgres.FormatPlain.Value.Key {
+		return params, fmt.Errorf(
+			"selective restores require a custom or directory archive",
+		)
+	}
+
+	if selection.DataOnly && selection.SchemaOnly {
+		return params, fmt.Errorf("data only and schema only can't be both set")
+	}
+
+	// Selected objects are restored into the target database, dropping or
+	// creating the whole database is not possible for part of the archive
+	params.Create = false
+	params.DataOnly = selection.DataOnly
+	params.SchemaOnly = selection.SchemaOnly
+	if selection.DataOnly {
+		params.Clean = false
+		params.IfExists = false
+	}
+
+	if len(selection.Schemas) == 0 && len(selection.Tables) == 0 {
+		return params, nil
+	}
+
+	entries, err := s.executionsService.ListExecutionTOC(ctx, execution.ID)
+	if err != nil {
+		return params, err
+	}
+
+	params.UseList = postgres.FilterTOC(
+		entries, selection.Schemas, selection.Tables,
+	)
+	if len(params.UseList) == 0 {
+		return params, fmt.Errorf(
+			"the selected schemas and tables were not found in the backup",
+		)
+	}
+
+	return params, nil
+}
+
+// createNewDatabase creates the new database using the maintenance connection
+// string and returns the connection string to the new database.
+func (s *Service) createNewDatabase(
+	ctx context.Context,
+	execution dbgen.ExecutionsServiceGetExecutionRow,
+	pgVersion postgres.PGVersion,
+	maintenanceConnString string,
+	newDatabase NewDatabaseParams,
+) (string, error) {
+	// With --create the dump creates and connects to the original database
+	if execution.OptCreate {
+		return "", fmt.Errorf(
+			"backups made with --create can't be restored into a new database",
+		)
+	}
+
+	err := s.ints.PGClient.CreateDatabase(
+		ctx, pgVersion, maintenanceConnString, newDatabase.CreateDatabaseParams,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return postgres.ReplaceDatabaseName(maintenanceConnString, newDatabase.Name)
+}
+
+// registerNewDatabase adds the new database to the databases table, it is
+// reached like the maintenance database, with the same SSH tunnel and TLS
+// files if any.
+func (s *Service) registerNewDatabase(
+	ctx context.Context,
+	maintenanceConnString string,
+	connParams postgres.ConnectionParams,
+	newDatabase NewDatabaseParams,
+) error {
+	connString, err := postgres.ReplaceDatabaseName(
+		maintenanceConnString, newDatabase.Name,
+	)
+	if err != nil {
+		return err
+	}
+
+	params := dbgen.DatabasesServiceCreateDatabaseParams{
+		Name:             newDatabase.Name,
+		ConnectionString: connString,
+	}
+	if tunnel := connParams.SSHTunnel; tunnel.IsEnabled() {
+		params.SshHost = sql.NullString{Valid: true, String: tunnel.Host}
+		params.SshUser = sql.NullString{Valid: true, String: tunnel.User}
+		params.SshPrivateKey = sql.NullString{Valid: true, String: tunnel.PrivateKey}
+		params.SshHostKey = sql.NullString{Valid: true, String: tunnel.HostKey}
+	}
+
+	tls := connParams.TLS
+	params.TlsRootCert = sql.NullString{
+		Valid: tls.RootCert != "", String: tls.RootCert,
+	}
+	params.TlsClientCert = sql.NullString{
+		Valid: tls.ClientCert != "", String: tls.ClientCert,
+	}
+	params.TlsClientKey = sql.NullString{
+		Valid: tls.ClientKey != "", String: tls.ClientKey,
+	}
+
+	_, err = s.databasesService.CreateDatabase(ctx, params)
+	return err
+}
+
+// applyGlobals restores a globals or roles backup execution so the roles and
+// tablespaces referenced by the database dump exist before restoring it.
+func (s *Service) applyGlobals(
+	ctx context.Context,
+	pgVersion postgres.PGVersion,
+	connString string,
+	globalsExecutionID uuid.UUID,
+) error {
+	execution, err := s.executionsService.GetExecution(ctx, globalsExecutionID)
+	if err != nil {
+		return err
+	}
+
+	if execution.BackupKind == "database" {
+		return fmt.Errorf("globals execution must belong to a globals or roles backup")
+	}
+
+	if execution.Status != "success" || !execution.Path.Valid {
+		return fmt.Errorf("globals execution must be successful")
+	}
+
+	err = s.restoreExecution(
+		ctx, execution, pgVersion, connString, postgres.RestoreParams{},
+	)
+	if err != nil {
+		return fmt.Errorf("error applying globals: %w", err)
+	}
+
+	return nil
+}
+
+// restoreExecution streams the file of the execution from its storage and
+// restores it with the format and compression recorded in the execution.
+func (s *Service) restoreExecution(
+	ctx context.Context,
+	execution dbgen.ExecutionsServiceGetExecutionRow,
+	pgVersion postgres.PGVersion,
+	connString string,
+	params postgres.RestoreParams,
+) error {
+	dumpFormat, err := s.ints.PGClient.ParseDumpFormat(execution.Format)
+	if err != nil {
+		return err
+	}
+
+	compression, err := s.ints.PGClient.ParseCompression(execution.Compression)
+	if err != nil {
+		return err
+	}
+
+	// The file is read twice so a corrupted file is never restored
+	err = s.executionsService.VerifyExecutionChecksum(ctx, execution.ID)
+	if err != nil {
+		return err
+	}
+
+	// Legacy ZIP files can't be streamed, they are read at random offsets
+	if compression == postgres.CompressionZip {
+		file, err := s.executionsService.OpenExecutionFileAt(ctx, execution.ID)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return s.ints.PGClient.RestoreZip(
+			ctx, pgVersion, connString, file, file.Size(), params,
+		)
+	}
+
+	file, err := s.executionsService.OpenDecryptedExecutionFile(ctx, execution.ID)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return s.ints.PGClient.Restore(
+		ctx, pgVersion, connString, file, dumpFormat, compression, params,
+	)
+}
@@ -0,0 +1,220 @@
+package restorations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// RunVerification runs a restore drill of the latest successful execution of
+// the given backup.
+//
+// The execution is restored into a scratch database created on the
+// verification server of the backup, the sanity checks of the backup are run
+// against it and the scratch database is dropped. The outcome is recorded on
+// the execution and the verification webhooks are fired.
+func (s *Service) RunVerification(ctx context.Context, backupID uuid.UUID) error {
+	logError := func(err error) {
+		logger.Error("error running verification", logger.KV{
+			"backup_id": backupID.String(),
+			"error":     err.Error(),
+		})
+	}
+
+	data, err := s.dbgen.RestorationsServiceGetVerificationData(ctx, backupID)
+	if err != nil {
+		logError(err)
+		return err
+	}
+
+	execution, err := s.executionsService.GetLatestSuccessfulExecution(
+		ctx, backupID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Info("no successful execution to verify", logger.KV{
+			"backup_id": backupID.String(),
+		})
+		return nil
+	}
+	if err != nil {
+		logError(err)
+		return err
+	}
+
+	updateExec := func(status string, message string) error {
+		if status == "success" {
+			s.webhooksService.RunVerificationSuccess(backupID)
+		}
+
+		if status == "failed" {
+			s.webhooksService.RunVerificationFailed(backupID)
+		}
+
+		_, err := s.executionsService.UpdateExecution(
+			ctx, dbgen.ExecutionsServiceUpdateExecutionParams{
+				ID:                  execution.ID,
+				VerificationStatus:  sql.NullString{Valid: true, String: status},
+				VerificationMessage: sql.NullString{Valid: true, String: message},
+				VerifiedAt:          sql.NullTime{Valid: true, Time: time.Now()},
+			},
+		)
+		return err
+	}
+
+	if err := updateExec("running", "Verification started"); err != nil {
+		logError(err)
+		return err
+	}
+
+	err = s.verifyExecution(ctx, execution.ID, data)
+	if err != nil {
+		logError(err)
+		return updateExec("failed", err.Error())
+	}
+
+	logger.Info("backup verified successfully", logger.KV{
+		"backup_id":    backupID.String(),
+		"execution_id": execution.ID.String(),
+	})
+	return updateExec("success", fmt.Sprintf(
+		"Backup restored and %d sanity checks passed", len(data.VerifyChecks),
+	))
+}
+
+// verifyExecution restores the execution into a scratch database of the
+// verification server, runs the sanity checks and drops the database.
+func (s *Service) verifyExecution(
+	ctx context.Context,
+	executionID uuid.UUID,
+	data dbgen.RestorationsServiceGetVerificationDataRow,
+) error {
+	if data.BackupKind != "database" {
+		return fmt.Errorf("only database backups can be verified")
+	}
+
+	if !data.VerifyDatabaseID.Valid {
+		return fmt.Errorf("the backup has no verification server")
+	}
+
+	execution, err := s.executionsService.GetExecution(ctx, executionID)
+	if err != nil {
+		return err
+	}
+
+	// A plain dump made with --create creates and connects to the original
+	// database, custom and directory archives are restored without it
+	if execution.Format == postgres.FormatPlain.Value.Key &&
+		execution.OptCreate {
+		return fmt.Errorf("plain backups made with --create can't be verified")
+	}
+
+	conn, err := s.databasesService.OpenConnection(
+		ctx, data.VerifyDatabaseID.UUID,
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	maintenanceConnString := conn.ConnString
+
+	pgVersion, err := s.ints.PGClient.ParseVersion(execution.DatabasePgVersion)
+	if err != nil {
+		return err
+	}
+
+	serverVersion, err := s.ints.PGClient.Test(ctx, maintenanceConnString)
+	if err != nil {
+		return err
+	}
+
+	// The binaries must be at least as new as the dump and the target server
+	if serverVersion.IsNewerThan(pgVersion) {
+		pgVersion = serverVersion
+	}
+
+	scratchName := "pbw_verify_" + strings.ReplaceAll(executionID.String(), "-", "")
+
+	// Drop the leftovers of an interrupted verification of this execution
+	err = s.ints.PGClient.DropDatabase(
+		ctx, pgVersion, maintenanceConnString, scratchName,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = s.ints.PGClient.CreateDatabase(
+		ctx, pgVersion, maintenanceConnString, postgres.CreateDatabaseParams{
+			Name:     scratchName,
+			Template: "template0",
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = s.restoreAndCheck(
+		ctx, execution, pgVersion, maintenanceConnString, scratchName,
+		data.VerifyChecks,
+	)
+
+	// The scratch database is dropped even if the verification was cancelled
+	dropErr := s.ints.PGClient.DropDatabase(
+		context.WithoutCancel(ctx), pgVersion, maintenanceConnString, scratchName,
+	)
+	return errors.Join(err, dropErr)
+}
+
+// restoreAndCheck restores the execution into the scratch database and runs
+// the sanity checks against it.
+func (s *Service) restoreAndCheck(
+	ctx context.Context,
+	execution dbgen.ExecutionsServiceGetExecutionRow,
+	pgVersion postgres.PGVersion,
+	maintenanceConnString string,
+	scratchName string,
+	checks []string,
+) error {
+	connString, err := postgres.ReplaceDatabaseName(
+		maintenanceConnString, scratchName,
+	)
+	if err != nil {
+		return err
+	}
+
+	params, err := s.restoreParams(ctx, execution, SelectionParams{})
+	if err != nil {
+		return err
+	}
+
+	// The scratch database is empty and the roles of the original server may
+	// not exist in the verification server. Any failed statement fails the
+	// verification, even if the checks pass without it.
+	params.Create = false
+	params.Clean = false
+	params.IfExists = false
+	params.NoOwner = true
+	params.NoPrivileges = true
+	params.ExitOnError = true
+
+	err = s.restoreExecution(ctx, execution, pgVersion, connString, params)
+	if err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		err := s.ints.PGClient.RunCheck(ctx, pgVersion, connString, check)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
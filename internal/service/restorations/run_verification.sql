@@ -0,0 +1,8 @@
+-- name: RestorationsServiceGetVerificationData :one
+SELECT
+  id AS backup_id,
+  kind AS backup_kind,
+  verify_database_id,
+  verify_checks
+FROM backups
+WHERE id = @backup_id;
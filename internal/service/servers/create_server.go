@@ -0,0 +1,79 @@
+package servers
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+// CreateServer creates a server and discovers its databases right away, the
+// result of the discovery is stored in the server.
+func (s *Service) CreateServer(
+	ctx context.Context, params dbgen.ServersServiceCreateServerParams,
+) (dbgen.Server, error) {
+	if err := validatePatterns(params.IncludePatterns); err != nil {
+		return dbgen.Server{}, err
+	}
+	if err := validatePatterns(params.ExcludePatterns); err != nil {
+		return dbgen.Server{}, err
+	}
+	if err := s.validateTemplate(ctx, params.TemplateBackupID); err != nil {
+		return dbgen.Server{}, err
+	}
+
+	params.IncludePatterns = nonNilSlice(params.IncludePatterns)
+	params.ExcludePatterns = nonNilSlice(params.ExcludePatterns)
+	server, err := s.dbgen.ServersServiceCreateServer(ctx, params)
+	if err != nil {
+		return server, err
+	}
+
+	if server.IsActive {
+		_ = s.syncServer(ctx, server.ID, true)
+	}
+
+	return server, nil
+}
+
+// validatePatterns returns an error if a database name pattern is malformed,
+// patterns use the shell glob syntax, for example app_* or tenant_[0-9]*.
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid database name pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// validateTemplate returns an error if the backup can't be used as the
+// template of a server.
+func (s *Service) validateTemplate(
+	ctx context.Context, templateBackupID uuid.UUID,
+) error {
+	template, err := s.backupsService.GetBackup(ctx, templateBackupID)
+	if err != nil {
+		return fmt.Errorf("error getting template backup: %w", err)
+	}
+
+	if template.Kind != "database" {
+		return fmt.Errorf("only database backups can be used as template")
+	}
+
+	if template.ServerID.Valid {
+		return fmt.Errorf("backups created by a server can't be used as template")
+	}
+
+	return nil
+}
+
+func nonNilSlice(slice []string) []string {
+	if slice == nil {
+		return []string{}
+	}
+	return slice
+}
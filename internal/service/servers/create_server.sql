@@ -0,0 +1,10 @@
+-- name: ServersServiceCreateServer :one
+INSERT INTO servers (
+  name, database_id, template_backup_id, include_patterns, exclude_patterns,
+  is_active
+)
+VALUES (
+  @name, @database_id, @template_backup_id, @include_patterns,
+  @exclude_patterns, @is_active
+)
+RETURNING *;
@@ -0,0 +1,15 @@
+package servers
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DeleteServer deletes a server, the databases and backups it created are
+// kept as regular ones.
+func (s *Service) DeleteServer(
+	ctx context.Context, id uuid.UUID,
+) error {
+	return s.dbgen.ServersServiceDeleteServer(ctx, id)
+}
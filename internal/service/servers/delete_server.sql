@@ -0,0 +1,3 @@
+-- name: ServersServiceDeleteServer :exec
+DELETE FROM servers
+WHERE id = @id;
@@ -0,0 +1,14 @@
+package servers
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+func (s *Service) GetServer(
+	ctx context.Context, id uuid.UUID,
+) (dbgen.Server, error) {
+	return s.dbgen.ServersServiceGetServer(ctx, id)
+}
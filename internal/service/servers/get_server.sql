@@ -0,0 +1,3 @@
+-- name: ServersServiceGetServer :one
+SELECT * FROM servers
+WHERE id = @id;
@@ -0,0 +1,44 @@
+package servers
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+)
+
+type PaginateServersParams struct {
+	Page  int
+	Limit int
+}
+
+func (s *Service) PaginateServers(
+	ctx context.Context, params PaginateServersParams,
+) (paginateutil.PaginateResponse, []dbgen.ServersServicePaginateServersRow, error) {
+	page := max(params.Page, 1)
+	limit := min(max(params.Limit, 1), 100)
+
+	count, err := s.dbgen.ServersServicePaginateServersCount(ctx)
+	if err != nil {
+		return paginateutil.PaginateResponse{}, nil, err
+	}
+
+	paginateParams := paginateutil.PaginateParams{
+		Page:  page,
+		Limit: limit,
+	}
+	offset := paginateutil.CreateOffsetFromParams(paginateParams)
+	paginateResponse := paginateutil.CreatePaginateResponse(paginateParams, int(count))
+
+	servers, err := s.dbgen.ServersServicePaginateServers(
+		ctx, dbgen.ServersServicePaginateServersParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		},
+	)
+	if err != nil {
+		return paginateutil.PaginateResponse{}, nil, err
+	}
+
+	return paginateResponse, servers, nil
+}
@@ -0,0 +1,22 @@
+-- name: ServersServicePaginateServersCount :one
+SELECT COUNT(*) FROM servers;
+
+-- name: ServersServicePaginateServers :many
+SELECT
+  servers.*,
+  databases.name AS database_name,
+  backups.name AS template_backup_name,
+  (
+    SELECT COUNT(*) FROM databases AS discovered
+    WHERE discovered.server_id = servers.id
+  ) AS databases_count,
+  (
+    SELECT COUNT(*) FROM databases AS discovered
+    WHERE discovered.server_id = servers.id
+    AND discovered.missing_since IS NOT NULL
+  ) AS missing_databases_count
+FROM servers
+INNER JOIN databases ON databases.id = servers.database_id
+INNER JOIN backups ON backups.id = servers.template_backup_id
+ORDER BY servers.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
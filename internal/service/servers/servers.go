@@ -0,0 +1,30 @@
+package servers
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/config"
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration"
+	"github.com/eduardolat/pgbackweb/internal/service/backups"
+	"github.com/eduardolat/pgbackweb/internal/service/databases"
+)
+
+type Service struct {
+	env              config.Env
+	dbgen            *dbgen.Queries
+	ints             *integration.Integration
+	databasesService *databases.Service
+	backupsService   *backups.Service
+}
+
+func New(
+	env config.Env, dbgen *dbgen.Queries, ints *integration.Integration,
+	databasesService *databases.Service, backupsService *backups.Service,
+) *Service {
+	return &Service{
+		env:              env,
+		dbgen:            dbgen,
+		ints:             ints,
+		databasesService: databasesService,
+		backupsService:   backupsService,
+	}
+}
@@ -0,0 +1,33 @@
+package servers
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/logger"
+)
+
+// SyncAllServers syncs the active servers, so databases created in them are
+// backed up and the changes of the templates are applied.
+func (s *Service) SyncAllServers() {
+	ctx := context.Background()
+
+	servers, err := s.dbgen.ServersServiceGetActiveServers(ctx)
+	if err != nil {
+		logger.Error(
+			"error getting active servers to sync them", logger.KV{"error": err},
+		)
+		return
+	}
+
+	for _, server := range servers {
+		err := s.syncServer(ctx, server.ID, false)
+		if err != nil {
+			logger.Error(
+				"error syncing server",
+				logger.KV{"server_id": server.ID, "error": err},
+			)
+		}
+	}
+
+	logger.Info("all servers synced")
+}
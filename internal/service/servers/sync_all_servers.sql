@@ -0,0 +1,4 @@
+-- name: ServersServiceGetActiveServers :many
+SELECT * FROM servers
+WHERE is_active = true
+ORDER BY created_at ASC;
@@ -0,0 +1,227 @@
+package servers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/backups"
+	"github.com/google/uuid"
+)
+
+// SyncServer discovers the databases of the server and applies the current
+// template to all of their backups, the result is stored in the server.
+func (s *Service) SyncServer(ctx context.Context, id uuid.UUID) error {
+	return s.syncServer(ctx, id, true)
+}
+
+// syncServer lists the databases of the server and, for every one that
+// matches the patterns, creates a database and a backup copied from the
+// template if they don't exist yet. Databases that are no longer in the
+// server, or no longer match, are flagged as missing and their backups are
+// left as they are.
+//
+// Existing backups are only updated if the template changed after them,
+// unless force is true.
+func (s *Service) syncServer(
+	ctx context.Context, id uuid.UUID, force bool,
+) error {
+	server, err := s.GetServer(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting server: %w", err)
+	}
+
+	err = s.discoverDatabases(ctx, server, force)
+
+	var syncError sql.NullString
+	if err != nil {
+		syncError = sql.NullString{String: err.Error(), Valid: true}
+	}
+	storeErr := s.dbgen.ServersServiceSetSyncResult(
+		ctx, dbgen.ServersServiceSetSyncResultParams{
+			ID:            server.ID,
+			LastSyncError: syncError,
+		},
+	)
+	if storeErr != nil {
+		return fmt.Errorf("error storing sync result: %w: %w", storeErr, err)
+	}
+
+	return err
+}
+
+func (s *Service) discoverDatabases(
+	ctx context.Context, server dbgen.Server, force bool,
+) error {
+	seed, err := s.databasesService.GetDatabase(ctx, server.DatabaseID)
+	if err != nil {
+		return fmt.Errorf("error getting database: %w", err)
+	}
+
+	version, err := s.ints.PGClient.ParseVersion(seed.PgVersion)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.databasesService.OpenConnection(ctx, server.DatabaseID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	names, err := s.ints.PGClient.ListDatabases(
+		ctx, version, conn.ConnString,
+	)
+	if err != nil {
+		return err
+	}
+
+	found := map[string]bool{}
+	for _, name := range names {
+		if matchPatterns(name, server.IncludePatterns, server.ExcludePatterns) {
+			found[name] = true
+		}
+	}
+
+	known, err := s.dbgen.ServersServiceGetServerDatabases(
+		ctx, uuid.NullUUID{UUID: server.ID, Valid: true},
+	)
+	if err != nil {
+		return fmt.Errorf("error getting server databases: %w", err)
+	}
+
+	var errs []error
+	databaseIDs := map[string]uuid.UUID{}
+
+	for _, db := range known {
+		name := db.ServerDatabaseName.String
+		missing := !found[name]
+		if !missing {
+			databaseIDs[name] = db.ID
+		}
+		if missing == db.MissingSince.Valid {
+			continue
+		}
+
+		err := s.dbgen.ServersServiceSetDatabaseMissing(
+			ctx, dbgen.ServersServiceSetDatabaseMissingParams{
+				ID:      db.ID,
+				Missing: missing,
+			},
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error flagging database %s: %w", name, err))
+		}
+	}
+
+	for _, name := range names {
+		if _, ok := databaseIDs[name]; ok || !found[name] {
+			continue
+		}
+
+		connString, err := postgres.ReplaceDatabaseName(
+			seed.DecryptedConnectionString, name,
+		)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		dbName, err := s.availableDatabaseName(
+			ctx, fmt.Sprintf("%s/%s", server.Name, name),
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error naming database %s: %w", name, err))
+			continue
+		}
+
+		db, err := s.dbgen.ServersServiceCreateServerDatabase(
+			ctx, dbgen.ServersServiceCreateServerDatabaseParams{
+				Name:               dbName,
+				ConnectionString:   connString,
+				EncryptionKey:      s.env.PBW_ENCRYPTION_KEY,
+				ServerID:           server.ID,
+				ServerDatabaseName: name,
+				DatabaseID:         seed.ID,
+			},
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error creating database %s: %w", name, err))
+			continue
+		}
+
+		// The backup is created anyway, the result is shown in the database
+		err = s.databasesService.TestDatabaseAndStoreResult(ctx, db.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error testing database %s: %w", name, err))
+		}
+		databaseIDs[name] = db.ID
+	}
+
+	for _, name := range names {
+		databaseID, ok := databaseIDs[name]
+		if !ok {
+			continue
+		}
+
+		err := s.backupsService.SyncServerBackup(
+			ctx, backups.SyncServerBackupParams{
+				ServerID:         server.ID,
+				TemplateBackupID: server.TemplateBackupID,
+				DatabaseID:       databaseID,
+				DatabaseName:     name,
+				Force:            force,
+			},
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error syncing backup of %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// availableDatabaseName returns the name, or the name followed by the first
+// free number like "name (2)" if a database already uses it, so databases
+// created by hand don't make every sync fail.
+func (s *Service) availableDatabaseName(
+	ctx context.Context, name string,
+) (string, error) {
+	candidate := name
+	for i := 2; ; i++ {
+		exists, err := s.dbgen.ServersServiceDatabaseNameExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s (%d)", name, i)
+	}
+}
+
+// matchPatterns returns true if the database name matches any include
+// pattern, or there are none, and doesn't match any exclude pattern.
+func matchPatterns(name string, include []string, exclude []string) bool {
+	for _, pattern := range exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+
+	if len(include) == 0 {
+		return true
+	}
+
+	for _, pattern := range include {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,46 @@
+-- name: ServersServiceGetServerDatabases :many
+SELECT id, server_database_name, missing_since
+FROM databases
+WHERE server_id = @server_id;
+
+-- name: ServersServiceDatabaseNameExists :one
+SELECT EXISTS (
+  SELECT 1 FROM databases WHERE name = @name
+)::BOOLEAN;
+
+-- name: ServersServiceCreateServerDatabase :one
+INSERT INTO databases (
+  name, connection_string, pg_version,
+  ssh_host, ssh_user, ssh_private_key, ssh_host_key,
+  tls_root_cert, tls_client_cert, tls_client_key,
+  server_id, server_database_name
+)
+SELECT
+  sqlc.arg('name')::TEXT,
+  pgp_sym_encrypt(
+    sqlc.arg('connection_string')::TEXT, sqlc.arg('encryption_key')::TEXT
+  ),
+  pg_version,
+  ssh_host, ssh_user, ssh_private_key, ssh_host_key,
+  tls_root_cert, tls_client_cert, tls_client_key,
+  sqlc.arg('server_id')::UUID, sqlc.arg('server_database_name')::TEXT
+FROM databases
+WHERE id = @database_id
+RETURNING *;
+
+-- name: ServersServiceSetDatabaseMissing :exec
+UPDATE databases
+SET missing_since = (
+  CASE WHEN @missing::BOOLEAN
+  THEN COALESCE(missing_since, NOW())
+  ELSE NULL
+  END
+)
+WHERE id = @id;
+
+-- name: ServersServiceSetSyncResult :exec
+UPDATE servers
+SET
+  last_sync_at = NOW(),
+  last_sync_error = sqlc.narg('last_sync_error')
+WHERE id = @id;
@@ -0,0 +1,38 @@
+package servers
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+)
+
+// UpdateServer updates a server and, if it is active, syncs it so the
+// backups of its databases use the new template and patterns. The database
+// used to connect to the server can't be changed.
+func (s *Service) UpdateServer(
+	ctx context.Context, params dbgen.ServersServiceUpdateServerParams,
+) (dbgen.Server, error) {
+	if err := validatePatterns(params.IncludePatterns); err != nil {
+		return dbgen.Server{}, err
+	}
+	if err := validatePatterns(params.ExcludePatterns); err != nil {
+		return dbgen.Server{}, err
+	}
+	if params.TemplateBackupID.Valid {
+		err := s.validateTemplate(ctx, params.TemplateBackupID.UUID)
+		if err != nil {
+			return dbgen.Server{}, err
+		}
+	}
+
+	server, err := s.dbgen.ServersServiceUpdateServer(ctx, params)
+	if err != nil {
+		return server, err
+	}
+
+	if server.IsActive {
+		_ = s.syncServer(ctx, server.ID, true)
+	}
+
+	return server, nil
+}
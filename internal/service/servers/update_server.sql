@@ -0,0 +1,10 @@
+-- name: ServersServiceUpdateServer :one
+UPDATE servers
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  template_backup_id = COALESCE(sqlc.narg('template_backup_id'), template_backup_id),
+  include_patterns = COALESCE(sqlc.narg('include_patterns')::TEXT[], include_patterns),
+  exclude_patterns = COALESCE(sqlc.narg('exclude_patterns')::TEXT[], exclude_patterns),
+  is_active = COALESCE(sqlc.narg('is_active'), is_active)
+WHERE id = @id
+RETURNING *;
@@ -11,7 +11,9 @@ import (
 	"github.com/eduardolat/pgbackweb/internal/service/destinations"
 	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/service/restorations"
+	"github.com/eduardolat/pgbackweb/internal/service/servers"
 	"github.com/eduardolat/pgbackweb/internal/service/users"
+	"github.com/eduardolat/pgbackweb/internal/service/walsegments"
 	"github.com/eduardolat/pgbackweb/internal/service/webhooks"
 )
 
@@ -23,7 +25,9 @@ type Service struct {
 	ExecutionsService   *executions.Service
 	UsersService        *users.Service
 	RestorationsService *restorations.Service
+	ServersService      *servers.Service
 	WebhooksService     *webhooks.Service
+	WalSegmentsService  *walsegments.Service
 }
 
 func New(
@@ -34,11 +38,23 @@ func New(
 	authService := auth.New(env, dbgen)
 	databasesService := databases.New(env, dbgen, ints, webhooksService)
 	destinationsService := destinations.New(env, dbgen, ints, webhooksService)
-	executionsService := executions.New(env, dbgen, ints, webhooksService)
+	executionsService := executions.New(
+		env, dbgen, ints, webhooksService, databasesService,
+	)
 	usersService := users.New(dbgen)
-	backupsService := backups.New(dbgen, cr, executionsService)
 	restorationsService := restorations.New(
 		dbgen, ints, executionsService, databasesService, destinationsService,
+		webhooksService,
+	)
+	walSegmentsService := walsegments.New(
+		env, dbgen, ints, databasesService, executionsService,
+	)
+	backupsService := backups.New(
+		env, dbgen, cr, executionsService, restorationsService,
+		walSegmentsService,
+	)
+	serversService := servers.New(
+		env, dbgen, ints, databasesService, backupsService,
 	)
 
 	return &Service{
@@ -49,6 +65,8 @@ func New(
 		ExecutionsService:   executionsService,
 		UsersService:        usersService,
 		RestorationsService: restorationsService,
+		ServersService:      serversService,
 		WebhooksService:     webhooksService,
+		WalSegmentsService:  walSegmentsService,
 	}
 }
@@ -0,0 +1,206 @@
+package walsegments
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/util/cryptoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/google/uuid"
+)
+
+// ArchiveAll keeps a pg_receivewal process running for every active physical
+// backup with WAL archiving enabled, and uploads the completed WAL files to
+// the destination of the backup.
+//
+// Receivers of backups that no longer archive WAL are stopped and their
+// replication slots are dropped.
+func (s *Service) ArchiveAll() {
+	if !s.receiversMu.TryLock() {
+		return
+	}
+	defer s.receiversMu.Unlock()
+
+	ctx := context.Background()
+
+	backups, err := s.dbgen.WalSegmentsServiceGetArchivingBackups(
+		ctx, s.env.PBW_ENCRYPTION_KEY,
+	)
+	if err != nil {
+		logger.Error("error getting backups to archive WAL", logger.KV{
+			"error": err,
+		})
+		return
+	}
+
+	archiving := map[uuid.UUID]bool{}
+	for _, back := range backups {
+		archiving[back.BackupID] = true
+		if err := s.archiveBackup(ctx, back); err != nil {
+			logger.Error("error archiving WAL", logger.KV{
+				"backup_id": back.BackupID.String(),
+				"error":     err,
+			})
+		}
+	}
+
+	for backupID, receiver := range s.receivers {
+		if archiving[backupID] {
+			continue
+		}
+		if err := s.stopArchiving(ctx, backupID); err != nil {
+			logger.Error("error stopping WAL archiving", logger.KV{
+				"backup_id": backupID.String(),
+				"error":     err,
+			})
+		}
+	}
+}
+
+// removeReceiver forgets the receiver of the backup and closes its
+// connection, the receiver must be already stopped.
+func (s *Service) removeReceiver(backupID uuid.UUID) {
+	delete(s.receivers, backupID)
+	delete(s.versions, backupID)
+	if conn, ok := s.connections[backupID]; ok {
+		_ = conn.Close()
+		delete(s.connections, backupID)
+	}
+}
+
+// archiveBackup makes sure the WAL receiver of the backup is running and
+// uploads the WAL files it has completed.
+func (s *Service) archiveBackup(
+	ctx context.Context, back dbgen.WalSegmentsServiceGetArchivingBackupsRow,
+) error {
+	spoolDir := s.ints.StorageClient.LocalGetFullPath(
+		strutil.CreatePath(false, ".wal-spool", back.BackupID.String()),
+	)
+
+	receiver, ok := s.receivers[back.BackupID]
+	if ok && !receiver.Running() {
+		if err := receiver.Err(); err != nil {
+			logger.Error("WAL receiver stopped", logger.KV{
+				"backup_id": back.BackupID.String(),
+				"error":     err,
+			})
+		}
+		s.removeReceiver(back.BackupID)
+		ok = false
+	}
+
+	if !ok {
+		pgVersion, err := s.ints.PGClient.ParseVersion(back.DatabasePgVersion)
+		if err != nil {
+			return err
+		}
+
+		// The connection stays open while the receiver is running
+		conn, err := s.databasesService.OpenConnection(ctx, back.DatabaseID)
+		if err != nil {
+			return err
+		}
+
+		receiver, err = s.ints.PGClient.StartWALReceiver(
+			ctx, pgVersion, conn.ConnString, spoolDir,
+			replicationSlotName(back.BackupID),
+		)
+		if err != nil {
+			_ = conn.Close()
+			return err
+		}
+		s.receivers[back.BackupID] = receiver
+		s.connections[back.BackupID] = conn
+		s.versions[back.BackupID] = pgVersion
+	}
+
+	entries, err := os.ReadDir(spoolDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !postgres.IsWALFile(entry.Name()) {
+			continue
+		}
+
+		if err := s.uploadSegment(ctx, back, spoolDir, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uploadSegment uploads a completed WAL file to the destination of the
+// backup, records it and removes it from the spool directory. The file is
+// encrypted if the backup has an encryption passphrase.
+func (s *Service) uploadSegment(
+	ctx context.Context, back dbgen.WalSegmentsServiceGetArchivingBackupsRow,
+	spoolDir string, entry os.DirEntry,
+) error {
+	localPath := strutil.CreatePath(true, spoolDir, entry.Name())
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	name := entry.Name()
+	var reader io.Reader = file
+	encrypted := back.DecryptedBackupEncryptionPassphrase != ""
+	if encrypted {
+		name += executions.EncryptedExtension
+		reader, err = cryptoutil.EncryptStream(
+			file, back.DecryptedBackupEncryptionPassphrase,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	path := strutil.CreatePath(false, back.BackupDestDir, "wal", name)
+	fileSize := int64(0)
+
+	if back.BackupIsLocal {
+		fileSize, err = s.ints.StorageClient.LocalUpload(path, reader)
+	} else {
+		fileSize, err = s.ints.StorageClient.S3Upload(
+			back.DecryptedDestinationAccessKey, back.DecryptedDestinationSecretKey,
+			back.DestinationRegion.String, back.DestinationEndpoint.String,
+			back.DestinationBucketName.String, path, reader,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.dbgen.WalSegmentsServiceCreateSegment(
+		ctx, dbgen.WalSegmentsServiceCreateSegmentParams{
+			BackupID:   back.BackupID,
+			Name:       entry.Name(),
+			Path:       path,
+			FileSize:   fileSize,
+			ArchivedAt: time.Now(),
+			Encrypted:  encrypted,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(localPath)
+}
+
+// replicationSlotName returns the name of the physical replication slot used
+// to archive the WAL of a backup.
+func replicationSlotName(backupID uuid.UUID) string {
+	return "pbw_" + strings.ReplaceAll(backupID.String(), "-", "")
+}
@@ -0,0 +1,56 @@
+-- name: WalSegmentsServiceGetArchivingBackups :many
+SELECT
+  backups.id as backup_id,
+  backups.is_local as backup_is_local,
+  backups.dest_dir as backup_dest_dir,
+  (
+    CASE WHEN backups.encryption_passphrase IS NOT NULL
+    THEN pgp_sym_decrypt(backups.encryption_passphrase, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_backup_encryption_passphrase,
+
+  databases.id as database_id,
+  databases.pg_version as database_pg_version,
+
+  destinations.bucket_name as destination_bucket_name,
+  destinations.region as destination_region,
+  destinations.endpoint as destination_endpoint,
+  (
+    CASE WHEN destinations.access_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.access_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_access_key,
+  (
+    CASE WHEN destinations.secret_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.secret_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_secret_key
+FROM backups
+INNER JOIN databases ON backups.database_id = databases.id
+LEFT JOIN destinations ON backups.destination_id = destinations.id
+WHERE backups.is_active = true
+AND backups.kind = 'physical'
+AND backups.opt_wal_archiving = true;
+
+-- name: WalSegmentsServiceCreateSegment :one
+INSERT INTO wal_segments (
+  backup_id, name, path, file_size, archived_at, encryption_passphrase
+)
+VALUES (
+  @backup_id, @name, @path, @file_size, @archived_at,
+  (
+    CASE WHEN sqlc.arg('encrypted')::BOOLEAN
+    THEN (SELECT encryption_passphrase FROM backups WHERE id = @backup_id)
+    ELSE NULL
+    END
+  )
+)
+ON CONFLICT (backup_id, name) DO UPDATE SET
+  path = EXCLUDED.path,
+  file_size = EXCLUDED.file_size,
+  archived_at = EXCLUDED.archived_at,
+  encryption_passphrase = EXCLUDED.encryption_passphrase
+RETURNING *;
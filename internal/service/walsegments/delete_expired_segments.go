@@ -0,0 +1,51 @@
+package walsegments
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/logger"
+)
+
+// DeleteExpiredSegments deletes the archived WAL files older than the oldest
+// base backup still available, they can't be used by any recovery.
+func (s *Service) DeleteExpiredSegments() {
+	ctx := context.Background()
+
+	segments, err := s.dbgen.WalSegmentsServiceGetExpiredSegments(
+		ctx, s.env.PBW_ENCRYPTION_KEY,
+	)
+	if err != nil {
+		logger.Error(
+			"error deleting expired WAL segments", logger.KV{"error": err},
+		)
+		return
+	}
+
+	for _, segment := range segments {
+		if segment.BackupIsLocal {
+			err = s.ints.StorageClient.LocalDelete(segment.Path)
+		} else {
+			err = s.ints.StorageClient.S3Delete(
+				segment.DecryptedDestinationAccessKey,
+				segment.DecryptedDestinationSecretKey,
+				segment.DestinationRegion.String, segment.DestinationEndpoint.String,
+				segment.DestinationBucketName.String, segment.Path,
+			)
+		}
+		if err != nil {
+			logger.Error("error deleting expired WAL segments", logger.KV{
+				"id": segment.ID.String(), "error": err,
+			})
+			return
+		}
+
+		if err := s.dbgen.WalSegmentsServiceDeleteSegment(ctx, segment.ID); err != nil {
+			logger.Error("error deleting expired WAL segments", logger.KV{
+				"id": segment.ID.String(), "error": err,
+			})
+			return
+		}
+	}
+
+	logger.Info("expired WAL segments deleted")
+}
@@ -0,0 +1,34 @@
+-- name: WalSegmentsServiceGetExpiredSegments :many
+SELECT
+  wal_segments.id,
+  wal_segments.path,
+  backups.is_local as backup_is_local,
+
+  destinations.bucket_name as destination_bucket_name,
+  destinations.region as destination_region,
+  destinations.endpoint as destination_endpoint,
+  (
+    CASE WHEN destinations.access_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.access_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_access_key,
+  (
+    CASE WHEN destinations.secret_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.secret_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_secret_key
+FROM wal_segments
+INNER JOIN backups ON backups.id = wal_segments.backup_id
+LEFT JOIN destinations ON destinations.id = backups.destination_id
+WHERE wal_segments.archived_at < (
+  SELECT MIN(executions.started_at)
+  FROM executions
+  WHERE executions.backup_id = wal_segments.backup_id
+  AND executions.kind = 'physical'
+  AND executions.status = 'success'
+);
+
+-- name: WalSegmentsServiceDeleteSegment :exec
+DELETE FROM wal_segments WHERE id = @id;
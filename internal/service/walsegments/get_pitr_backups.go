@@ -0,0 +1,15 @@
+package walsegments
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+)
+
+// GetPITRBackups returns the physical backups that archive WAL, they can be
+// recovered to a point in time.
+func (s *Service) GetPITRBackups(
+	ctx context.Context,
+) ([]dbgen.WalSegmentsServiceGetPITRBackupsRow, error) {
+	return s.dbgen.WalSegmentsServiceGetPITRBackups(ctx)
+}
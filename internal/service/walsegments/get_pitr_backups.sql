@@ -0,0 +1,10 @@
+-- name: WalSegmentsServiceGetPITRBackups :many
+SELECT
+  backups.id,
+  backups.name,
+  databases.name AS database_name
+FROM backups
+INNER JOIN databases ON databases.id = backups.database_id
+WHERE backups.kind = 'physical'
+AND backups.opt_wal_archiving = true
+ORDER BY backups.name ASC;
@@ -0,0 +1,52 @@
+package walsegments
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// StopArchiving stops the WAL receiver of the backup, if it is running, and
+// drops its replication slot so the server no longer keeps WAL for it. It
+// must be called when a backup stops archiving WAL or before it is deleted.
+func (s *Service) StopArchiving(ctx context.Context, backupID uuid.UUID) error {
+	s.receiversMu.Lock()
+	defer s.receiversMu.Unlock()
+
+	return s.stopArchiving(ctx, backupID)
+}
+
+// stopArchiving is StopArchiving without locking receiversMu. The connection
+// of a running receiver is reused, so the slot can be dropped even if the
+// backup was already deleted.
+func (s *Service) stopArchiving(ctx context.Context, backupID uuid.UUID) error {
+	slot := replicationSlotName(backupID)
+
+	if receiver, ok := s.receivers[backupID]; ok {
+		receiver.Stop()
+		err := s.ints.PGClient.DropReplicationSlot(
+			s.versions[backupID], s.connections[backupID].ConnString, slot,
+		)
+		s.removeReceiver(backupID)
+		return err
+	}
+
+	back, err := s.dbgen.WalSegmentsServiceGetBackupDatabase(ctx, backupID)
+	if err != nil {
+		return fmt.Errorf("error getting backup database: %w", err)
+	}
+
+	pgVersion, err := s.ints.PGClient.ParseVersion(back.DatabasePgVersion)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.databasesService.OpenConnection(ctx, back.DatabaseID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return s.ints.PGClient.DropReplicationSlot(pgVersion, conn.ConnString, slot)
+}
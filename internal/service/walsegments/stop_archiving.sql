@@ -0,0 +1,7 @@
+-- name: WalSegmentsServiceGetBackupDatabase :one
+SELECT
+  databases.id as database_id,
+  databases.pg_version as database_pg_version
+FROM backups
+INNER JOIN databases ON backups.database_id = databases.id
+WHERE backups.id = @backup_id;
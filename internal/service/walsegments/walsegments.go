@@ -0,0 +1,45 @@
+package walsegments
+
+import (
+	"sync"
+
+	"github.com/eduardolat/pgbackweb/internal/config"
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/databases"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	env   config.Env
+	dbgen *dbgen.Queries
+	ints  *integration.Integration
+
+	databasesService  *databases.Service
+	executionsService *executions.Service
+
+	// receiversMu guards receivers, connections and versions and avoids
+	// overlapping archive runs
+	receiversMu sync.Mutex
+	receivers   map[uuid.UUID]*postgres.WALReceiver
+	connections map[uuid.UUID]*postgres.Connection
+	versions    map[uuid.UUID]postgres.PGVersion
+}
+
+func New(
+	env config.Env, dbgen *dbgen.Queries, ints *integration.Integration,
+	databasesService *databases.Service, executionsService *executions.Service,
+) *Service {
+	return &Service{
+		env:               env,
+		dbgen:             dbgen,
+		ints:              ints,
+		databasesService:  databasesService,
+		executionsService: executionsService,
+		receivers:         map[uuid.UUID]*postgres.WALReceiver{},
+		connections:       map[uuid.UUID]*postgres.Connection{},
+		versions:          map[uuid.UUID]postgres.PGVersion{},
+	}
+}
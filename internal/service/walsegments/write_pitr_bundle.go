@@ -0,0 +1,202 @@
+package walsegments
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/util/cryptoutil"
+	"github.com/google/uuid"
+)
+
+// WritePITRBundle writes into w a ZIP file with everything needed to recover
+// the cluster of a physical backup to the given target time: the latest base
+// backup finished before the target time, the archived WAL files from the
+// start of that base backup until the target time, the recovery settings and
+// the instructions to restore it.
+//
+// Encrypted base backups and WAL files are decrypted, so the bundle can be
+// restored as it is.
+func (s *Service) WritePITRBundle(
+	ctx context.Context, w io.Writer, backupID uuid.UUID, targetTime time.Time,
+) error {
+	back, err := s.dbgen.WalSegmentsServiceGetPITRBackupData(
+		ctx, dbgen.WalSegmentsServiceGetPITRBackupDataParams{
+			BackupID:      backupID,
+			EncryptionKey: s.env.PBW_ENCRYPTION_KEY,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	pgVersion, err := s.ints.PGClient.ParseVersion(back.DatabasePgVersion)
+	if err != nil {
+		return err
+	}
+
+	base, err := s.dbgen.WalSegmentsServiceGetPITRBaseExecution(
+		ctx, dbgen.WalSegmentsServiceGetPITRBaseExecutionParams{
+			BackupID:   backupID,
+			TargetTime: sql.NullTime{Valid: true, Time: targetTime},
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no successful physical backup finished before the target time")
+	}
+	if err != nil {
+		return err
+	}
+
+	segments, err := s.dbgen.WalSegmentsServiceListSegmentsSince(
+		ctx, dbgen.WalSegmentsServiceListSegmentsSinceParams{
+			BackupID:      backupID,
+			Since:         base.StartedAt,
+			EncryptionKey: s.env.PBW_ENCRYPTION_KEY,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Keep the segments until the first one archived after the target time,
+	// which holds the last transactions that must be replayed
+	coveredUntil := -1
+	for i, segment := range segments {
+		if !segment.ArchivedAt.Before(targetTime) {
+			coveredUntil = i
+			break
+		}
+	}
+	if coveredUntil == -1 {
+		return fmt.Errorf(
+			"the WAL archive doesn't cover the target time yet, try again later",
+		)
+	}
+	segments = segments[:coveredUntil+1]
+
+	zipWriter := zip.NewWriter(w)
+
+	files := []bundleFile{
+		{
+			name: "RECOVERY.txt",
+			content: stringContent(
+				postgres.PITRRecoveryInstructions(pgVersion, targetTime),
+			),
+		},
+		{
+			name:    "recovery.conf",
+			content: stringContent(postgres.PITRRecoveryConf(targetTime)),
+		},
+		{
+			name: "base-backup.zip",
+			content: func() (io.ReadCloser, error) {
+				return s.executionsService.OpenDecryptedExecutionFile(ctx, base.ID)
+			},
+		},
+	}
+	for _, segment := range segments {
+		path := segment.Path
+		passphrase := segment.DecryptedEncryptionPassphrase
+		files = append(files, bundleFile{
+			name: "wal/" + segment.Name,
+			content: func() (io.ReadCloser, error) {
+				return s.openDecryptedFile(back, path, passphrase)
+			},
+		})
+	}
+
+	for _, file := range files {
+		if err := writeBundleFile(zipWriter, file); err != nil {
+			return err
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+// openFile opens a file stored in the destination of the backup.
+func (s *Service) openFile(
+	back dbgen.WalSegmentsServiceGetPITRBackupDataRow, path string,
+) (io.ReadCloser, error) {
+	if back.BackupIsLocal {
+		return os.Open(s.ints.StorageClient.LocalGetFullPath(path))
+	}
+
+	return s.ints.StorageClient.S3Download(
+		back.DecryptedDestinationAccessKey, back.DecryptedDestinationSecretKey,
+		back.DestinationRegion.String, back.DestinationEndpoint.String,
+		back.DestinationBucketName.String, path,
+	)
+}
+
+// openDecryptedFile works like openFile but decrypts the file on the fly if
+// it was encrypted with the given passphrase.
+func (s *Service) openDecryptedFile(
+	back dbgen.WalSegmentsServiceGetPITRBackupDataRow, path string,
+	passphrase string,
+) (io.ReadCloser, error) {
+	file, err := s.openFile(back, path)
+	if err != nil || passphrase == "" {
+		return file, err
+	}
+
+	decrypted, err := cryptoutil.DecryptStream(file, passphrase)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return decryptedFile{Reader: decrypted, Closer: file}, nil
+}
+
+// decryptedFile reads the decrypted content and closes the underlying file.
+type decryptedFile struct {
+	io.Reader
+	io.Closer
+}
+
+type bundleFile struct {
+	name    string
+	content func() (io.ReadCloser, error)
+}
+
+func writeBundleFile(zipWriter *zip.Writer, file bundleFile) error {
+	name := file.name
+	reader, err := file.content()
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", name, err)
+	}
+	defer reader.Close()
+
+	// Base backups and WAL files are stored without compression to save CPU,
+	// the base backup is already compressed
+	fileWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating %s in bundle: %w", name, err)
+	}
+
+	if _, err := io.Copy(fileWriter, reader); err != nil {
+		return fmt.Errorf("error writing %s to bundle: %w", name, err)
+	}
+
+	return nil
+}
+
+func stringContent(content string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+}
@@ -0,0 +1,50 @@
+-- name: WalSegmentsServiceGetPITRBackupData :one
+SELECT
+  backups.id as backup_id,
+  backups.is_local as backup_is_local,
+  databases.pg_version as database_pg_version,
+
+  destinations.bucket_name as destination_bucket_name,
+  destinations.region as destination_region,
+  destinations.endpoint as destination_endpoint,
+  (
+    CASE WHEN destinations.access_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.access_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_access_key,
+  (
+    CASE WHEN destinations.secret_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.secret_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_secret_key
+FROM backups
+INNER JOIN databases ON backups.database_id = databases.id
+LEFT JOIN destinations ON backups.destination_id = destinations.id
+WHERE backups.id = @backup_id
+AND backups.kind = 'physical';
+
+-- name: WalSegmentsServiceGetPITRBaseExecution :one
+SELECT * FROM executions
+WHERE backup_id = @backup_id
+AND kind = 'physical'
+AND status = 'success'
+AND path IS NOT NULL
+AND finished_at <= @target_time
+ORDER BY finished_at DESC
+LIMIT 1;
+
+-- name: WalSegmentsServiceListSegmentsSince :many
+SELECT
+  *,
+  (
+    CASE WHEN encryption_passphrase IS NOT NULL
+    THEN pgp_sym_decrypt(encryption_passphrase, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_encryption_passphrase
+FROM wal_segments
+WHERE backup_id = @backup_id
+AND archived_at >= @since
+ORDER BY archived_at ASC, name ASC;
@@ -58,6 +58,24 @@ func (s *Service) RunExecutionFailed(backupID uuid.UUID) {
 	}()
 }
 
+// RunVerificationSuccess runs the verification success webhooks for the
+// given backup ID.
+func (s *Service) RunVerificationSuccess(backupID uuid.UUID) {
+	go func() {
+		ctx := context.Background()
+		runWebhook(s, ctx, EventTypeVerificationSuccess, backupID)
+	}()
+}
+
+// RunVerificationFailed runs the verification failed webhooks for the given
+// backup ID.
+func (s *Service) RunVerificationFailed(backupID uuid.UUID) {
+	go func() {
+		ctx := context.Background()
+		runWebhook(s, ctx, EventTypeVerificationFailed, backupID)
+	}()
+}
+
 // runWebhook runs the webhooks for the given event type and target ID.
 func runWebhook(
 	s *Service, ctx context.Context, eventType eventType, targetID uuid.UUID,
@@ -34,6 +34,13 @@ var (
 	EventTypeExecutionFailed = eventType{
 		Value: eventTypeData{Key: "execution_failed", Name: "Execution failed"},
 	}
+
+	EventTypeVerificationSuccess = eventType{
+		Value: eventTypeData{Key: "verification_success", Name: "Verification success"},
+	}
+	EventTypeVerificationFailed = eventType{
+		Value: eventTypeData{Key: "verification_failed", Name: "Verification failed"},
+	}
 )
 
 var FullEventTypes = map[string]string{
@@ -43,6 +50,8 @@ var FullEventTypes = map[string]string{
 	EventTypeDestinationUnhealthy.Value.Key: EventTypeDestinationUnhealthy.Value.Name,
 	EventTypeExecutionSuccess.Value.Key:     EventTypeExecutionSuccess.Value.Name,
 	EventTypeExecutionFailed.Value.Key:      EventTypeExecutionFailed.Value.Name,
+	EventTypeVerificationSuccess.Value.Key:  EventTypeVerificationSuccess.Value.Name,
+	EventTypeVerificationFailed.Value.Key:   EventTypeVerificationFailed.Value.Name,
 }
 
 type Service struct {
@@ -0,0 +1,214 @@
+package cryptoutil
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted streams start with streamMagic, followed by the scrypt salt and
+// the nonce prefix. The content is split in chunks of streamChunkSize bytes,
+// each one sealed with AES-256-GCM using the nonce prefix, the chunk number
+// and a flag that marks the last chunk, so chunks can't be reordered and a
+// truncated stream is detected.
+//
+// A stream whose length is a multiple of streamChunkSize ends with an empty
+// last chunk, so full chunks are never the last one.
+const (
+	streamMagic      = "PBWENC1\n"
+	streamSaltSize   = 16
+	streamPrefixSize = 7
+	streamChunkSize  = 64 * 1024
+)
+
+// ErrWrongPassphrase is returned when an encrypted stream can't be
+// authenticated, because the passphrase is wrong or the stream was modified.
+var ErrWrongPassphrase = errors.New(
+	"error decrypting stream: wrong passphrase or corrupted data",
+)
+
+// EncryptStream returns the content of the reader encrypted with AES-256-GCM
+// using a key derived from the passphrase with scrypt.
+func EncryptStream(r io.Reader, passphrase string) (io.Reader, error) {
+	header := make([]byte, len(streamMagic)+streamSaltSize+streamPrefixSize)
+	copy(header, streamMagic)
+	if _, err := rand.Read(header[len(streamMagic):]); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+
+	salt := header[len(streamMagic) : len(streamMagic)+streamSaltSize]
+	prefix := header[len(streamMagic)+streamSaltSize:]
+
+	aead, err := newStreamAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptReader{
+		r:      r,
+		aead:   aead,
+		prefix: prefix,
+		out:    header,
+		buf:    make([]byte, streamChunkSize),
+	}, nil
+}
+
+// DecryptStream returns the content of a reader encrypted by EncryptStream.
+// Reading returns ErrWrongPassphrase if a chunk can't be authenticated.
+func DecryptStream(r io.Reader, passphrase string) (io.Reader, error) {
+	header := make([]byte, len(streamMagic)+streamSaltSize+streamPrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("error reading encrypted stream header: %w", err)
+	}
+	if string(header[:len(streamMagic)]) != streamMagic {
+		return nil, fmt.Errorf("the stream is not encrypted by PG Back Web")
+	}
+
+	salt := header[len(streamMagic) : len(streamMagic)+streamSaltSize]
+	prefix := header[len(streamMagic)+streamSaltSize:]
+
+	aead, err := newStreamAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		prefix: prefix,
+		buf:    make([]byte, streamChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// newStreamAEAD derives the AES-256 key from the passphrase and the salt.
+func newStreamAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("encryption passphrase is required")
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// streamNonce returns the nonce of the chunk with the given number.
+func streamNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, streamPrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+type encryptReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	done    bool
+
+	// out holds the sealed bytes not returned yet
+	out []byte
+	buf []byte
+}
+
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		if err := e.sealChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// sealChunk reads and encrypts the next chunk of the content.
+func (e *encryptReader) sealChunk() error {
+	n, err := io.ReadFull(e.r, e.buf)
+	last := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	if err != nil && !last {
+		return err
+	}
+
+	if e.counter == ^uint32(0) {
+		return fmt.Errorf("the stream is too large to be encrypted")
+	}
+
+	nonce := streamNonce(e.prefix, e.counter, last)
+	e.out = e.aead.Seal(e.out[:0], nonce, e.buf[:n], nil)
+	e.counter++
+	e.done = last
+	return nil
+}
+
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	done    bool
+
+	// out holds the opened bytes not returned yet
+	out []byte
+	buf []byte
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.openChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// openChunk reads and decrypts the next chunk of the stream.
+func (d *decryptReader) openChunk() error {
+	n, err := io.ReadFull(d.r, d.buf)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("encrypted stream is truncated: %w", io.ErrUnexpectedEOF)
+	}
+	last := errors.Is(err, io.ErrUnexpectedEOF)
+	if err != nil && !last {
+		return err
+	}
+
+	nonce := streamNonce(d.prefix, d.counter, last)
+	out, err := d.aead.Open(d.buf[:0], nonce, d.buf[:n], nil)
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+
+	d.out = out
+	d.counter++
+	d.done = last
+	return nil
+}
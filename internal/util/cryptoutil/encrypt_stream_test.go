@@ -0,0 +1,90 @@
+package cryptoutil
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptStream(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "Empty content", size: 0},
+		{name: "Single byte", size: 1},
+		{name: "Smaller than a chunk", size: streamChunkSize - 1},
+		{name: "Exactly one chunk", size: streamChunkSize},
+		{name: "Larger than a chunk", size: streamChunkSize + 1},
+		{name: "Several chunks", size: 3*streamChunkSize + 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := make([]byte, tt.size)
+			_, err := rand.Read(content)
+			assert.NoError(t, err)
+
+			encrypted, err := EncryptStream(bytes.NewReader(content), "secret")
+			assert.NoError(t, err)
+			ciphertext, err := io.ReadAll(encrypted)
+			assert.NoError(t, err)
+			if tt.size >= 16 {
+				assert.False(t, bytes.Contains(ciphertext, content))
+			}
+
+			decrypted, err := DecryptStream(bytes.NewReader(ciphertext), "secret")
+			assert.NoError(t, err)
+			plaintext, err := io.ReadAll(decrypted)
+			assert.NoError(t, err)
+			assert.Equal(t, content, plaintext)
+		})
+	}
+}
+
+func TestDecryptStream_Errors(t *testing.T) {
+	content := bytes.Repeat([]byte("pg back web "), streamChunkSize/4)
+
+	encrypted, err := EncryptStream(bytes.NewReader(content), "secret")
+	assert.NoError(t, err)
+	ciphertext, err := io.ReadAll(encrypted)
+	assert.NoError(t, err)
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)/2] ^= 0xff
+
+	// Cut at the end of the first chunk, it looks like a complete stream
+	// until the last chunk is missing
+	headerSize := len(streamMagic) + streamSaltSize + streamPrefixSize
+	truncated := ciphertext[:headerSize+streamChunkSize+16]
+
+	tests := []struct {
+		name       string
+		ciphertext []byte
+		passphrase string
+	}{
+		{name: "Wrong passphrase", ciphertext: ciphertext, passphrase: "wrong"},
+		{name: "Tampered content", ciphertext: tampered, passphrase: "secret"},
+		{name: "Truncated stream", ciphertext: truncated, passphrase: "secret"},
+		{name: "Not encrypted", ciphertext: content, passphrase: "secret"},
+		{name: "Empty passphrase", ciphertext: ciphertext, passphrase: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decrypted, err := DecryptStream(bytes.NewReader(tt.ciphertext), tt.passphrase)
+			if err == nil {
+				_, err = io.ReadAll(decrypted)
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEncryptStream_EmptyPassphrase(t *testing.T) {
+	_, err := EncryptStream(bytes.NewReader([]byte("content")), "")
+	assert.Error(t, err)
+}
@@ -58,5 +58,21 @@ func GetContentTypeFromFileName(fileName string) string {
 		return "application/sql"
 	}
 
+	if strings.HasSuffix(fileName, ".gz") {
+		return "application/gzip"
+	}
+
+	if strings.HasSuffix(fileName, ".zst") {
+		return "application/zstd"
+	}
+
+	if strings.HasSuffix(fileName, ".lz4") {
+		return "application/x-lz4"
+	}
+
+	if strings.HasSuffix(fileName, ".tar") {
+		return "application/x-tar"
+	}
+
 	return "application/octet-stream"
 }
@@ -24,6 +24,10 @@ func TestGetContentTypeFromFileName(t *testing.T) {
 		{"pagina.html", "text/html"},
 		{"archivo.zip", "application/zip"},
 		{"archivo.sql", "application/sql"},
+		{"archivo.sql.gz", "application/gzip"},
+		{"archivo.dump.zst", "application/zstd"},
+		{"archivo.tar.lz4", "application/x-lz4"},
+		{"archivo.tar", "application/x-tar"},
 		{"archivo.desconocido", "application/octet-stream"}, // unknown extension
 		{"MAYUSCULAS.JPG", "image/jpeg"},                    // upper case
 		{"MezclaDeMayusculasYMinusculas.PnG", "image/png"},  // mixed case
@@ -0,0 +1,21 @@
+package strutil
+
+import "strings"
+
+// SplitLines splits a string into lines, trimming the spaces of each line and
+// skipping the empty ones.
+//
+// It always returns a non-nil slice.
+func SplitLines(str string) []string {
+	lines := []string{}
+
+	for _, line := range strings.Split(str, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
@@ -0,0 +1,42 @@
+package strutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want []string
+	}{
+		{
+			name: "Empty string",
+			str:  "",
+			want: []string{},
+		},
+		{
+			name: "Only blank lines",
+			str:  "\n  \n\t\n",
+			want: []string{},
+		},
+		{
+			name: "Single line",
+			str:  "public",
+			want: []string{"public"},
+		},
+		{
+			name: "Multiple lines with spaces and empty lines",
+			str:  "  public \n\naudit.*\r\n  logs_2024  \n",
+			want: []string{"public", "audit.*", "logs_2024"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SplitLines(tt.str))
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package backups
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/validate"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -103,6 +107,7 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 	var requestBody struct {
 		DatabaseID     string `json:"database_id"`
 		DestinationID  string `json:"destination_id"`
+		Kind           string `json:"kind" validate:"omitempty,oneof=database globals roles physical"`
 		IsLocal        bool   `json:"is_local"`
 		Name           string `json:"name"`
 		CronExpression string `json:"cron_expression"`
@@ -116,6 +121,42 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 		OptIfExists    bool   `json:"opt_if_exists"`
 		OptCreate      bool   `json:"opt_create"`
 		OptNoComments  bool   `json:"opt_no_comments"`
+		OptFormat      string `json:"opt_format" validate:"omitempty,oneof=plain custom directory"`
+		OptJobs        int16  `json:"opt_jobs" validate:"omitempty,min=1,max=32"`
+
+		// TimeoutMinutes is 0 to use the PBW_BACKUP_TIMEOUT default
+		TimeoutMinutes int32 `json:"timeout_minutes" validate:"min=0"`
+
+		OptWalArchiving bool `json:"opt_wal_archiving"`
+
+		OptCompression      string `json:"opt_compression" validate:"omitempty,oneof=none gzip zstd lz4"`
+		OptCompressionLevel int16  `json:"opt_compression_level" validate:"min=0,max=19"`
+
+		OptSchemas          []string `json:"opt_schemas"`
+		OptExcludeSchemas   []string `json:"opt_exclude_schemas"`
+		OptTables           []string `json:"opt_tables"`
+		OptExcludeTables    []string `json:"opt_exclude_tables"`
+		OptExcludeTableData []string `json:"opt_exclude_table_data"`
+
+		OptDumpOptions postgres.DumpOptions `json:"opt_dump_options"`
+
+		PreHookSQL         string `json:"pre_hook_sql"`
+		PreHookCommand     string `json:"pre_hook_command"`
+		PostHookSQL        string `json:"post_hook_sql"`
+		PostHookCommand    string `json:"post_hook_command"`
+		HookTimeoutSeconds int32  `json:"hook_timeout_seconds" validate:"omitempty,min=1,max=86400"`
+		HookFailurePolicy  string `json:"hook_failure_policy" validate:"omitempty,oneof=abort continue"`
+
+		RetryMaxAttempts         int32   `json:"retry_max_attempts" validate:"omitempty,min=1,max=10"`
+		RetryInitialDelaySeconds int32   `json:"retry_initial_delay_seconds" validate:"omitempty,min=1,max=86400"`
+		RetryBackoffFactor       float64 `json:"retry_backoff_factor" validate:"omitempty,min=1,max=10"`
+
+		VerifyIsActive       bool     `json:"verify_is_active"`
+		VerifyCronExpression string   `json:"verify_cron_expression"`
+		VerifyDatabaseID     string   `json:"verify_database_id"`
+		VerifyChecks         []string `json:"verify_checks"`
+
+		EncryptionPassphrase string `json:"encryption_passphrase"`
 	}
 	if err := json.NewDecoder(c.Request().Body).Decode(&requestBody); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -123,6 +164,12 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 		})
 	}
 
+	if err := validate.Struct(&requestBody); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
 	// Parse UUIDs
 	databaseID, err := uuid.Parse(requestBody.DatabaseID)
 	if err != nil {
@@ -142,10 +189,68 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 		destinationID = uuid.NullUUID{UUID: id, Valid: true}
 	}
 
+	var verifyDatabaseID uuid.NullUUID
+	if requestBody.VerifyDatabaseID != "" {
+		id, err := uuid.Parse(requestBody.VerifyDatabaseID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid verification database ID",
+			})
+		}
+		verifyDatabaseID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	// Database backups made with pg_dump are the default kind
+	if requestBody.Kind == "" {
+		requestBody.Kind = "database"
+	}
+
+	// Plain SQL is the default dump format
+	if requestBody.OptFormat == "" {
+		requestBody.OptFormat = "plain"
+	}
+
+	// gzip is the default compression, it matches the database default
+	if requestBody.OptCompression == "" {
+		requestBody.OptCompression = "gzip"
+	}
+
+	// A single job is the default, parallel jobs need the directory format
+	if requestBody.OptJobs == 0 {
+		requestBody.OptJobs = 1
+	}
+
+	// Hooks have five minutes to finish and fail the execution by default
+	if requestBody.HookTimeoutSeconds == 0 {
+		requestBody.HookTimeoutSeconds = 300
+	}
+	if requestBody.HookFailurePolicy == "" {
+		requestBody.HookFailurePolicy = "abort"
+	}
+
+	// Failed executions are not retried by default
+	if requestBody.RetryMaxAttempts == 0 {
+		requestBody.RetryMaxAttempts = 1
+	}
+	if requestBody.RetryInitialDelaySeconds == 0 {
+		requestBody.RetryInitialDelaySeconds = 60
+	}
+	if requestBody.RetryBackoffFactor == 0 {
+		requestBody.RetryBackoffFactor = 2
+	}
+
+	dumpOptions, err := json.Marshal(requestBody.OptDumpOptions)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid dump options: " + err.Error(),
+		})
+	}
+
 	// Create backup in database
 	backup, err := h.servs.BackupsService.CreateBackup(ctx, dbgen.BackupsServiceCreateBackupParams{
 		DatabaseID:     databaseID,
 		DestinationID:  destinationID,
+		Kind:           requestBody.Kind,
 		IsLocal:        requestBody.IsLocal,
 		Name:           requestBody.Name,
 		CronExpression: requestBody.CronExpression,
@@ -159,6 +264,44 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 		OptIfExists:    requestBody.OptIfExists,
 		OptCreate:      requestBody.OptCreate,
 		OptNoComments:  requestBody.OptNoComments,
+		OptFormat:      requestBody.OptFormat,
+		OptJobs:        requestBody.OptJobs,
+
+		TimeoutMinutes: requestBody.TimeoutMinutes,
+
+		OptWalArchiving: requestBody.OptWalArchiving,
+
+		OptCompression:      requestBody.OptCompression,
+		OptCompressionLevel: requestBody.OptCompressionLevel,
+
+		OptSchemas:          requestBody.OptSchemas,
+		OptExcludeSchemas:   requestBody.OptExcludeSchemas,
+		OptTables:           requestBody.OptTables,
+		OptExcludeTables:    requestBody.OptExcludeTables,
+		OptExcludeTableData: requestBody.OptExcludeTableData,
+
+		OptDumpOptions: string(dumpOptions),
+
+		PreHookSql:         requestBody.PreHookSQL,
+		PreHookCommand:     requestBody.PreHookCommand,
+		PostHookSql:        requestBody.PostHookSQL,
+		PostHookCommand:    requestBody.PostHookCommand,
+		HookTimeoutSeconds: requestBody.HookTimeoutSeconds,
+		HookFailurePolicy:  requestBody.HookFailurePolicy,
+
+		RetryMaxAttempts:         requestBody.RetryMaxAttempts,
+		RetryInitialDelaySeconds: requestBody.RetryInitialDelaySeconds,
+		RetryBackoffFactor:       requestBody.RetryBackoffFactor,
+
+		VerifyIsActive:       requestBody.VerifyIsActive,
+		VerifyCronExpression: requestBody.VerifyCronExpression,
+		VerifyDatabaseID:     verifyDatabaseID,
+		VerifyChecks:         requestBody.VerifyChecks,
+
+		EncryptionPassphrase: sql.NullString{
+			Valid:  requestBody.EncryptionPassphrase != "",
+			String: requestBody.EncryptionPassphrase,
+		},
 	})
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -176,11 +319,9 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Backup ID"
-// @Success 200 {object} map[string]string
+// @Success 202 {object} map[string]string
 // @Router /api/backups/{id}/trigger [post]
 func (h *handlers) triggerBackupHandler(c echo.Context) error {
-	ctx := c.Request().Context()
-
 	// Get backup ID from URL parameter
 	backupIDStr := c.Param("id")
 	backupID, err := uuid.Parse(backupIDStr)
@@ -190,15 +331,13 @@ func (h *handlers) triggerBackupHandler(c echo.Context) error {
 		})
 	}
 
-	// Trigger the backup
-	err = h.servs.ExecutionsService.RunExecution(ctx, backupID)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to trigger backup: " + err.Error(),
-		})
-	}
+	// The execution can take hours with its retries, it runs in the
+	// background and is not cancelled when the request ends
+	go func() {
+		_ = h.servs.ExecutionsService.RunExecution(context.Background(), backupID)
+	}()
 
-	return c.JSON(http.StatusOK, map[string]string{
+	return c.JSON(http.StatusAccepted, map[string]string{
 		"message": "Backup task triggered successfully",
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/google/uuid"
@@ -162,8 +163,6 @@ func (h *mockHandlers) createBackupHandler(c echo.Context) error {
 
 // triggerBackupHandler is a copy of the original handler but using our mock types
 func (h *mockHandlers) triggerBackupHandler(c echo.Context) error {
-	ctx := c.Request().Context()
-
 	// Get backup ID from URL parameter
 	backupIDStr := c.Param("id")
 	backupID, err := uuid.Parse(backupIDStr)
@@ -173,15 +172,13 @@ func (h *mockHandlers) triggerBackupHandler(c echo.Context) error {
 		})
 	}
 
-	// Trigger the backup
-	err = h.servs.ExecutionsService.RunExecution(ctx, backupID)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to trigger backup: " + err.Error(),
-		})
-	}
+	// The execution can take hours with its retries, it runs in the
+	// background and is not cancelled when the request ends
+	go func() {
+		_ = h.servs.ExecutionsService.RunExecution(context.Background(), backupID)
+	}()
 
-	return c.JSON(http.StatusOK, map[string]string{
+	return c.JSON(http.StatusAccepted, map[string]string{
 		"message": "Backup task triggered successfully",
 	})
 }
@@ -720,6 +717,9 @@ func TestTriggerBackupHandler(t *testing.T) {
 		},
 	}
 
+	// The context the execution runs with, sent when it starts
+	started := make(chan context.Context, 1)
+
 	// Test cases
 	tests := []struct {
 		name           string
@@ -727,18 +727,41 @@ func TestTriggerBackupHandler(t *testing.T) {
 		mockSetup      func()
 		expectedStatus int
 		expectedBody   map[string]interface{}
+		expectRun      bool
 	}{
 		{
 			name:     "Success - Trigger backup",
 			backupID: "123e4567-e89b-12d3-a456-426614174000",
 			mockSetup: func() {
 				backupID, _ := uuid.Parse("123e4567-e89b-12d3-a456-426614174000")
-				mockExecutionsService.On("RunExecution", mock.Anything, backupID).Return(nil)
+				mockExecutionsService.On("RunExecution", mock.Anything, backupID).
+					Run(func(args mock.Arguments) {
+						started <- args.Get(0).(context.Context)
+					}).
+					Return(nil)
 			},
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusAccepted,
 			expectedBody: map[string]interface{}{
 				"message": "Backup task triggered successfully",
 			},
+			expectRun: true,
+		},
+		{
+			name:     "Success - Failed execution is not reported",
+			backupID: "123e4567-e89b-12d3-a456-426614174000",
+			mockSetup: func() {
+				backupID, _ := uuid.Parse("123e4567-e89b-12d3-a456-426614174000")
+				mockExecutionsService.On("RunExecution", mock.Anything, backupID).
+					Run(func(args mock.Arguments) {
+						started <- args.Get(0).(context.Context)
+					}).
+					Return(assert.AnError)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody: map[string]interface{}{
+				"message": "Backup task triggered successfully",
+			},
+			expectRun: true,
 		},
 		{
 			name:           "Error - Invalid backup ID",
@@ -749,27 +772,20 @@ func TestTriggerBackupHandler(t *testing.T) {
 				"error": "Invalid backup ID",
 			},
 		},
-		{
-			name:     "Error - Run execution fails",
-			backupID: "123e4567-e89b-12d3-a456-426614174000",
-			mockSetup: func() {
-				backupID, _ := uuid.Parse("123e4567-e89b-12d3-a456-426614174000")
-				mockExecutionsService.On("RunExecution", mock.Anything, backupID).Return(assert.AnError)
-			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody: map[string]interface{}{
-				"error": "Failed to trigger backup: " + assert.AnError.Error(),
-			},
-		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			// Setup mock
+			// Setup mock, a new one for every case because the execution may
+			// still be running in the background
+			mockExecutionsService = new(MockExecutionsService)
+			h.servs.ExecutionsService = mockExecutionsService
 			tc.mockSetup()
 
 			// Create request
+			reqCtx, cancelReq := context.WithCancel(context.Background())
 			req := httptest.NewRequest(http.MethodPost, "/api/backups/"+tc.backupID+"/trigger", nil)
+			req = req.WithContext(reqCtx)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 			c.SetParamNames("id")
@@ -777,6 +793,17 @@ func TestTriggerBackupHandler(t *testing.T) {
 
 			// Test handler
 			err := h.triggerBackupHandler(c)
+			cancelReq()
+
+			// The execution outlives the request
+			if tc.expectRun {
+				select {
+				case runCtx := <-started:
+					assert.NoError(t, runCtx.Err())
+				case <-time.After(time.Second):
+					t.Fatal("execution was not started")
+				}
+			}
 
 			// Assertions
 			assert.NoError(t, err)
@@ -786,9 +813,6 @@ func TestTriggerBackupHandler(t *testing.T) {
 			err = json.Unmarshal(rec.Body.Bytes(), &response)
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expectedBody, response)
-
-			// Reset mock for next test
-			mockExecutionsService.ExpectedCalls = nil
 		})
 	}
 }
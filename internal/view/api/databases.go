@@ -1,18 +1,33 @@
 package api
 
 import (
+	"database/sql"
 	"net/http"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
 type createDatabaseRequest struct {
-	Name             string `json:"name" validate:"required"`
-	Version          string `json:"version" validate:"required,oneof=13 14 15 16"`
+	Name string `json:"name" validate:"required"`
+	// Deprecated: the version is detected from the server, it is only kept
+	// for backwards compatibility and ignored.
+	Version          string `json:"version" validate:"omitempty,number"`
 	ConnectionString string `json:"connection_string" validate:"required"`
+
+	// Optional SSH tunnel to reach the database through a bastion host
+	SSHHost       string `json:"ssh_host"`
+	SSHUser       string `json:"ssh_user"`
+	SSHPrivateKey string `json:"ssh_private_key"`
+	SSHHostKey    string `json:"ssh_host_key"`
+
+	// Optional PEM encoded files to connect with TLS
+	TLSRootCert   string `json:"tls_root_cert"`
+	TLSClientCert string `json:"tls_client_cert"`
+	TLSClientKey  string `json:"tls_client_key"`
 }
 
 type databaseResponse struct {
@@ -38,8 +53,25 @@ func (h *handlers) createDatabaseAPI(c echo.Context) error {
 		})
 	}
 
+	connParams := postgres.ConnectionParams{
+		SSHTunnel: postgres.SSHTunnelParams{
+			Host:       req.SSHHost,
+			User:       req.SSHUser,
+			PrivateKey: req.SSHPrivateKey,
+			HostKey:    req.SSHHostKey,
+		},
+		TLS: postgres.TLSParams{
+			RootCert:   req.TLSRootCert,
+			ClientCert: req.TLSClientCert,
+			ClientKey:  req.TLSClientKey,
+		},
+	}
+	hasTunnel := connParams.SSHTunnel.IsEnabled()
+
 	// Test the database connection first
-	if err := h.servs.DatabasesService.TestDatabase(ctx, req.Version, req.ConnectionString); err != nil {
+	if _, err := h.servs.DatabasesService.TestDatabaseWithParams(
+		ctx, req.ConnectionString, connParams,
+	); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "Failed to connect to database: " + err.Error(),
 		})
@@ -49,8 +81,14 @@ func (h *handlers) createDatabaseAPI(c echo.Context) error {
 	db, err := h.servs.DatabasesService.CreateDatabase(
 		ctx, dbgen.DatabasesServiceCreateDatabaseParams{
 			Name:             req.Name,
-			PgVersion:        req.Version,
 			ConnectionString: req.ConnectionString,
+			SshHost:          sql.NullString{String: req.SSHHost, Valid: hasTunnel},
+			SshUser:          sql.NullString{String: req.SSHUser, Valid: hasTunnel},
+			SshPrivateKey:    sql.NullString{String: req.SSHPrivateKey, Valid: hasTunnel},
+			SshHostKey:       sql.NullString{String: req.SSHHostKey, Valid: hasTunnel},
+			TlsRootCert:      sql.NullString{String: req.TLSRootCert, Valid: req.TLSRootCert != ""},
+			TlsClientCert:    sql.NullString{String: req.TLSClientCert, Valid: req.TLSClientCert != ""},
+			TlsClientKey:     sql.NullString{String: req.TLSClientKey, Valid: req.TLSClientKey != ""},
 		},
 	)
 	if err != nil {
@@ -60,3 +60,69 @@ func (h *handlers) listExecutionsHandler(c echo.Context) error {
 		"pagination": paginateResponse,
 	})
 }
+
+// ExecutionContents godoc
+// @Summary List the contents of an execution
+// @Description List the objects contained in the file of a successful execution, like schemas, tables with their approximate data size, functions and extensions. The whole file is read, so it can take a while for big backups.
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} map[string]interface{} "Returns the objects of the execution"
+// @Failure 400 {object} map[string]string "Invalid execution ID"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/executions/{id}/contents [get]
+func (h *handlers) executionContentsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Get execution ID from URL parameter
+	executionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid execution ID",
+		})
+	}
+
+	objects, err := h.servs.ExecutionsService.InspectExecution(ctx, executionID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to read execution contents: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": objects,
+	})
+}
+
+// CancelExecution godoc
+// @Summary Cancel a running execution
+// @Description Stop a running backup execution, the partial file is discarded and the post-backup hooks still run. Executions left running by a previous process are marked as cancelled.
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} map[string]string "Execution cancelled"
+// @Failure 400 {object} map[string]string "Invalid execution ID or the execution is not running"
+// @Router /api/executions/{id}/cancel [post]
+func (h *handlers) cancelExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Get execution ID from URL parameter
+	executionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid execution ID",
+		})
+	}
+
+	if err := h.servs.ExecutionsService.CancelExecution(ctx, executionID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Failed to cancel execution: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Execution cancelled",
+	})
+}
@@ -3,11 +3,13 @@ package executions
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
 	"github.com/google/uuid"
@@ -19,6 +21,8 @@ import (
 // ExecutionsServiceInterface defines the interface for the ExecutionsService
 type ExecutionsServiceInterface interface {
 	PaginateExecutions(ctx context.Context, params executions.PaginateExecutionsParams) (paginateutil.PaginateResponse, []dbgen.ExecutionsServicePaginateExecutionsRow, error)
+	InspectExecution(ctx context.Context, executionID uuid.UUID) ([]postgres.DumpObject, error)
+	CancelExecution(ctx context.Context, executionID uuid.UUID) error
 }
 
 // MockExecutionsService is a mock implementation of the ExecutionsServiceInterface
@@ -31,6 +35,16 @@ func (m *MockExecutionsService) PaginateExecutions(ctx context.Context, params e
 	return args.Get(0).(paginateutil.PaginateResponse), args.Get(1).([]dbgen.ExecutionsServicePaginateExecutionsRow), args.Error(2)
 }
 
+func (m *MockExecutionsService) InspectExecution(ctx context.Context, executionID uuid.UUID) ([]postgres.DumpObject, error) {
+	args := m.Called(ctx, executionID)
+	return args.Get(0).([]postgres.DumpObject), args.Error(1)
+}
+
+func (m *MockExecutionsService) CancelExecution(ctx context.Context, executionID uuid.UUID) error {
+	args := m.Called(ctx, executionID)
+	return args.Error(0)
+}
+
 // mockHandlers is a test version of handlers that accepts interfaces
 type mockHandlers struct {
 	servs *mockService
@@ -75,6 +89,53 @@ func (h *mockHandlers) listExecutionsHandler(c echo.Context) error {
 	})
 }
 
+// executionContentsHandler is a copy of the original handler but using our mock types
+func (h *mockHandlers) executionContentsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Get execution ID from URL parameter
+	executionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid execution ID",
+		})
+	}
+
+	objects, err := h.servs.ExecutionsService.InspectExecution(ctx, executionID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to read execution contents: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": objects,
+	})
+}
+
+// cancelExecutionHandler is a copy of the original handler but using our mock types
+func (h *mockHandlers) cancelExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Get execution ID from URL parameter
+	executionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid execution ID",
+		})
+	}
+
+	if err := h.servs.ExecutionsService.CancelExecution(ctx, executionID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Failed to cancel execution: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Execution cancelled",
+	})
+}
+
 func TestListExecutionsHandler(t *testing.T) {
 	// Setup
 	e := echo.New()
@@ -225,3 +286,187 @@ func TestListExecutionsHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutionContentsHandler(t *testing.T) {
+	// Setup
+	e := echo.New()
+	mockExecutionsService := new(MockExecutionsService)
+	h := &mockHandlers{
+		servs: &mockService{
+			ExecutionsService: mockExecutionsService,
+		},
+	}
+
+	executionID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	// Test cases
+	tests := []struct {
+		name           string
+		executionID    string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:        "Success - List execution contents",
+			executionID: executionID.String(),
+			mockSetup: func() {
+				mockExecutionsService.On("InspectExecution", mock.Anything, executionID).Return(
+					[]postgres.DumpObject{
+						{Type: "TABLE", Schema: "public", Name: "users", Owner: "postgres", DataSize: 1024},
+					},
+					nil,
+				)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{
+						"type":      "TABLE",
+						"schema":    "public",
+						"name":      "users",
+						"owner":     "postgres",
+						"data_size": float64(1024),
+					},
+				},
+			},
+		},
+		{
+			name:           "Error - Invalid execution ID",
+			executionID:    "invalid",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid execution ID",
+			},
+		},
+		{
+			name:        "Error - Service error",
+			executionID: executionID.String(),
+			mockSetup: func() {
+				mockExecutionsService.On("InspectExecution", mock.Anything, executionID).Return(
+					[]postgres.DumpObject(nil),
+					assert.AnError,
+				)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: map[string]interface{}{
+				"error": "Failed to read execution contents: " + assert.AnError.Error(),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup mock
+			tc.mockSetup()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodGet, "/api/executions/"+tc.executionID+"/contents", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.executionID)
+
+			// Test handler
+			err := h.executionContentsHandler(c)
+
+			// Assertions
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBody, response)
+
+			// Reset mock for next test
+			mockExecutionsService.ExpectedCalls = nil
+		})
+	}
+}
+
+func TestCancelExecutionHandler(t *testing.T) {
+	// Setup
+	e := echo.New()
+	mockExecutionsService := new(MockExecutionsService)
+	h := &mockHandlers{
+		servs: &mockService{
+			ExecutionsService: mockExecutionsService,
+		},
+	}
+
+	executionID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	// Test cases
+	tests := []struct {
+		name           string
+		executionID    string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:        "Success - Cancel execution",
+			executionID: executionID.String(),
+			mockSetup: func() {
+				mockExecutionsService.On("CancelExecution", mock.Anything, executionID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"message": "Execution cancelled",
+			},
+		},
+		{
+			name:           "Error - Invalid execution ID",
+			executionID:    "invalid",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid execution ID",
+			},
+		},
+		{
+			name:        "Error - Execution not running",
+			executionID: executionID.String(),
+			mockSetup: func() {
+				mockExecutionsService.On("CancelExecution", mock.Anything, executionID).Return(
+					errors.New("execution is not running"),
+				)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Failed to cancel execution: execution is not running",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup mock
+			tc.mockSetup()
+
+			// Create request
+			req := httptest.NewRequest(http.MethodPost, "/api/executions/"+tc.executionID+"/cancel", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.executionID)
+
+			// Test handler
+			err := h.cancelExecutionHandler(c)
+
+			// Assertions
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBody, response)
+
+			// Reset mock for next test
+			mockExecutionsService.ExpectedCalls = nil
+		})
+	}
+}
@@ -9,4 +9,6 @@ func MountRouter(parent *echo.Group, servs *service.Service) {
 	h := newHandlers(servs)
 
 	parent.GET("", h.listExecutionsHandler)
+	parent.GET("/:id/contents", h.executionContentsHandler)
+	parent.POST("/:id/cancel", h.cancelExecutionHandler)
 }
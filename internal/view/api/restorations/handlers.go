@@ -1,8 +1,11 @@
 package restorations
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/service"
 	"github.com/eduardolat/pgbackweb/internal/service/restorations"
 	"github.com/google/uuid"
@@ -69,3 +72,161 @@ func (h *handlers) listRestorationsHandler(c echo.Context) error {
 		"data": restorations,
 	})
 }
+
+// CreateRestoration godoc
+// @Summary Start a restoration
+// @Description Restore a backup execution into a registered database, a connection string or a new database. Custom and directory archives can be restored partially picking schemas or tables, and only their data or schema.
+// @Tags restorations
+// @Accept json
+// @Produce json
+// @Param restoration body object true "Restoration parameters"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /api/restorations [post]
+func (h *handlers) createRestorationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Parse request body
+	var requestBody struct {
+		ExecutionID        string `json:"execution_id"`
+		DatabaseID         string `json:"database_id"`
+		ConnectionString   string `json:"connection_string"`
+		GlobalsExecutionID string `json:"globals_execution_id"`
+
+		NewDatabaseName     string `json:"new_database_name"`
+		NewDatabaseOwner    string `json:"new_database_owner"`
+		NewDatabaseTemplate string `json:"new_database_template"`
+		RegisterDatabase    bool   `json:"register_database"`
+
+		Schemas    []string `json:"schemas"`
+		Tables     []string `json:"tables"`
+		DataOnly   bool     `json:"data_only"`
+		SchemaOnly bool     `json:"schema_only"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&requestBody); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body: " + err.Error(),
+		})
+	}
+
+	// Parse UUIDs
+	executionID, err := uuid.Parse(requestBody.ExecutionID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid execution ID",
+		})
+	}
+
+	var databaseID uuid.NullUUID
+	if requestBody.DatabaseID != "" {
+		id, err := uuid.Parse(requestBody.DatabaseID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid database ID",
+			})
+		}
+		databaseID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	var globalsExecutionID uuid.NullUUID
+	if requestBody.GlobalsExecutionID != "" {
+		id, err := uuid.Parse(requestBody.GlobalsExecutionID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid globals execution ID",
+			})
+		}
+		globalsExecutionID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	if databaseID.Valid == (requestBody.ConnectionString != "") {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Exactly one of database_id or connection_string is required",
+		})
+	}
+
+	if requestBody.DataOnly && requestBody.SchemaOnly {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "data_only and schema_only cannot be both set",
+		})
+	}
+
+	if _, err := h.servs.ExecutionsService.GetExecution(ctx, executionID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Failed to get execution: " + err.Error(),
+		})
+	}
+
+	if requestBody.ConnectionString != "" {
+		_, err := h.servs.DatabasesService.TestDatabase(
+			ctx, requestBody.ConnectionString,
+		)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	// The restoration can take a long time, its result is stored in the
+	// restorations list
+	go func() {
+		_ = h.servs.RestorationsService.RunRestoration(
+			context.Background(),
+			executionID,
+			databaseID,
+			requestBody.ConnectionString,
+			globalsExecutionID,
+			restorations.NewDatabaseParams{
+				CreateDatabaseParams: postgres.CreateDatabaseParams{
+					Name:     requestBody.NewDatabaseName,
+					Owner:    requestBody.NewDatabaseOwner,
+					Template: requestBody.NewDatabaseTemplate,
+				},
+				Register: requestBody.RegisterDatabase,
+			},
+			restorations.SelectionParams{
+				Schemas:    requestBody.Schemas,
+				Tables:     requestBody.Tables,
+				DataOnly:   requestBody.DataOnly,
+				SchemaOnly: requestBody.SchemaOnly,
+			},
+		)
+	}()
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "Restoration started, check the restorations list for more details",
+	})
+}
+
+// CancelRestoration godoc
+// @Summary Cancel a running restoration
+// @Description Stop a running restoration, the objects already restored are not rolled back. Restorations left running by a previous process are marked as cancelled.
+// @Tags restorations
+// @Accept json
+// @Produce json
+// @Param id path string true "Restoration ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /api/restorations/{id}/cancel [post]
+func (h *handlers) cancelRestorationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Get restoration ID from URL parameter
+	restorationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid restoration ID",
+		})
+	}
+
+	if err := h.servs.RestorationsService.CancelRestoration(ctx, restorationID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Failed to cancel restoration: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Restoration cancelled",
+	})
+}
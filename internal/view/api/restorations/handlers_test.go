@@ -3,11 +3,15 @@ package restorations
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/service/restorations"
 	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
 	"github.com/google/uuid"
@@ -19,11 +23,19 @@ import (
 // RestorationsServiceInterface defines the interface for the RestorationsService
 type RestorationsServiceInterface interface {
 	PaginateRestorations(ctx context.Context, params restorations.PaginateRestorationsParams) (paginateutil.PaginateResponse, []dbgen.RestorationsServicePaginateRestorationsRow, error)
+	RunRestoration(ctx context.Context, executionID uuid.UUID, databaseID uuid.NullUUID, connString string, globalsExecutionID uuid.NullUUID, newDatabase restorations.NewDatabaseParams, selection restorations.SelectionParams) error
+	CancelRestoration(ctx context.Context, restorationID uuid.UUID) error
 }
 
 // ExecutionsServiceInterface defines the interface for the ExecutionsService
 type ExecutionsServiceInterface interface {
 	RunExecution(ctx context.Context, backupID uuid.UUID) error
+	GetExecution(ctx context.Context, id uuid.UUID) (dbgen.ExecutionsServiceGetExecutionRow, error)
+}
+
+// DatabasesServiceInterface defines the interface for the DatabasesService
+type DatabasesServiceInterface interface {
+	TestDatabase(ctx context.Context, connString string) (postgres.PGVersion, error)
 }
 
 // MockRestorationsService is a mock implementation of the RestorationsServiceInterface
@@ -36,6 +48,16 @@ func (m *MockRestorationsService) PaginateRestorations(ctx context.Context, para
 	return args.Get(0).(paginateutil.PaginateResponse), args.Get(1).([]dbgen.RestorationsServicePaginateRestorationsRow), args.Error(2)
 }
 
+func (m *MockRestorationsService) RunRestoration(ctx context.Context, executionID uuid.UUID, databaseID uuid.NullUUID, connString string, globalsExecutionID uuid.NullUUID, newDatabase restorations.NewDatabaseParams, selection restorations.SelectionParams) error {
+	args := m.Called(ctx, executionID, databaseID, connString, globalsExecutionID, newDatabase, selection)
+	return args.Error(0)
+}
+
+func (m *MockRestorationsService) CancelRestoration(ctx context.Context, restorationID uuid.UUID) error {
+	args := m.Called(ctx, restorationID)
+	return args.Error(0)
+}
+
 // MockExecutionsService is a mock implementation of the ExecutionsServiceInterface
 type MockExecutionsService struct {
 	mock.Mock
@@ -46,6 +68,21 @@ func (m *MockExecutionsService) RunExecution(ctx context.Context, backupID uuid.
 	return args.Error(0)
 }
 
+func (m *MockExecutionsService) GetExecution(ctx context.Context, id uuid.UUID) (dbgen.ExecutionsServiceGetExecutionRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(dbgen.ExecutionsServiceGetExecutionRow), args.Error(1)
+}
+
+// MockDatabasesService is a mock implementation of the DatabasesServiceInterface
+type MockDatabasesService struct {
+	mock.Mock
+}
+
+func (m *MockDatabasesService) TestDatabase(ctx context.Context, connString string) (postgres.PGVersion, error) {
+	args := m.Called(ctx, connString)
+	return args.Get(0).(postgres.PGVersion), args.Error(1)
+}
+
 // mockHandlers is a test version of handlers that accepts interfaces
 type mockHandlers struct {
 	servs *mockService
@@ -55,6 +92,7 @@ type mockHandlers struct {
 type mockService struct {
 	RestorationsService RestorationsServiceInterface
 	ExecutionsService   ExecutionsServiceInterface
+	DatabasesService    DatabasesServiceInterface
 }
 
 // listRestorationsHandler is a copy of the original handler but using our mock types
@@ -127,6 +165,146 @@ func (h *mockHandlers) triggerBackupHandler(c echo.Context) error {
 	})
 }
 
+// createRestorationHandler is a copy of the original handler but using our mock types
+func (h *mockHandlers) createRestorationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Parse request body
+	var requestBody struct {
+		ExecutionID        string `json:"execution_id"`
+		DatabaseID         string `json:"database_id"`
+		ConnectionString   string `json:"connection_string"`
+		GlobalsExecutionID string `json:"globals_execution_id"`
+
+		NewDatabaseName     string `json:"new_database_name"`
+		NewDatabaseOwner    string `json:"new_database_owner"`
+		NewDatabaseTemplate string `json:"new_database_template"`
+		RegisterDatabase    bool   `json:"register_database"`
+
+		Schemas    []string `json:"schemas"`
+		Tables     []string `json:"tables"`
+		DataOnly   bool     `json:"data_only"`
+		SchemaOnly bool     `json:"schema_only"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&requestBody); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body: " + err.Error(),
+		})
+	}
+
+	// Parse UUIDs
+	executionID, err := uuid.Parse(requestBody.ExecutionID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid execution ID",
+		})
+	}
+
+	var databaseID uuid.NullUUID
+	if requestBody.DatabaseID != "" {
+		id, err := uuid.Parse(requestBody.DatabaseID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid database ID",
+			})
+		}
+		databaseID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	var globalsExecutionID uuid.NullUUID
+	if requestBody.GlobalsExecutionID != "" {
+		id, err := uuid.Parse(requestBody.GlobalsExecutionID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid globals execution ID",
+			})
+		}
+		globalsExecutionID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	if databaseID.Valid == (requestBody.ConnectionString != "") {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Exactly one of database_id or connection_string is required",
+		})
+	}
+
+	if requestBody.DataOnly && requestBody.SchemaOnly {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "data_only and schema_only cannot be both set",
+		})
+	}
+
+	if _, err := h.servs.ExecutionsService.GetExecution(ctx, executionID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Failed to get execution: " + err.Error(),
+		})
+	}
+
+	if requestBody.ConnectionString != "" {
+		_, err := h.servs.DatabasesService.TestDatabase(
+			ctx, requestBody.ConnectionString,
+		)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	// The restoration can take a long time, its result is stored in the
+	// restorations list
+	go func() {
+		_ = h.servs.RestorationsService.RunRestoration(
+			context.Background(),
+			executionID,
+			databaseID,
+			requestBody.ConnectionString,
+			globalsExecutionID,
+			restorations.NewDatabaseParams{
+				CreateDatabaseParams: postgres.CreateDatabaseParams{
+					Name:     requestBody.NewDatabaseName,
+					Owner:    requestBody.NewDatabaseOwner,
+					Template: requestBody.NewDatabaseTemplate,
+				},
+				Register: requestBody.RegisterDatabase,
+			},
+			restorations.SelectionParams{
+				Schemas:    requestBody.Schemas,
+				Tables:     requestBody.Tables,
+				DataOnly:   requestBody.DataOnly,
+				SchemaOnly: requestBody.SchemaOnly,
+			},
+		)
+	}()
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "Restoration started, check the restorations list for more details",
+	})
+}
+
+// cancelRestorationHandler is a copy of the original handler but using our mock types
+func (h *mockHandlers) cancelRestorationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Get restoration ID from URL parameter
+	restorationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid restoration ID",
+		})
+	}
+
+	if err := h.servs.RestorationsService.CancelRestoration(ctx, restorationID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Failed to cancel restoration: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Restoration cancelled",
+	})
+}
+
 func TestListRestorationsHandler(t *testing.T) {
 	// Setup
 	e := echo.New()
@@ -313,3 +491,202 @@ func TestTriggerBackupHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateRestorationHandler(t *testing.T) {
+	executionID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+	databaseID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174001")
+	started := make(chan struct{}, 1)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockRestorationsService, *MockExecutionsService, *MockDatabasesService)
+		expectedStatus int
+		expectedBody   map[string]interface{}
+		expectRun      bool
+	}{
+		{
+			name: "Success - Selective restore into a registered database",
+			body: `{"execution_id":"123e4567-e89b-12d3-a456-426614174000","database_id":"123e4567-e89b-12d3-a456-426614174001","tables":["public.users"],"data_only":true}`,
+			mockSetup: func(rs *MockRestorationsService, es *MockExecutionsService, _ *MockDatabasesService) {
+				es.On("GetExecution", mock.Anything, executionID).Return(dbgen.ExecutionsServiceGetExecutionRow{}, nil)
+				rs.On(
+					"RunRestoration", mock.Anything, executionID,
+					uuid.NullUUID{UUID: databaseID, Valid: true}, "", uuid.NullUUID{},
+					restorations.NewDatabaseParams{},
+					restorations.SelectionParams{Tables: []string{"public.users"}, DataOnly: true},
+				).Run(func(mock.Arguments) { started <- struct{}{} }).Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody: map[string]interface{}{
+				"message": "Restoration started, check the restorations list for more details",
+			},
+			expectRun: true,
+		},
+		{
+			name:           "Error - Invalid execution ID",
+			body:           `{"execution_id":"invalid","database_id":"123e4567-e89b-12d3-a456-426614174001"}`,
+			mockSetup:      func(*MockRestorationsService, *MockExecutionsService, *MockDatabasesService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid execution ID",
+			},
+		},
+		{
+			name:           "Error - Database and connection string",
+			body:           `{"execution_id":"123e4567-e89b-12d3-a456-426614174000","database_id":"123e4567-e89b-12d3-a456-426614174001","connection_string":"postgresql://localhost/db"}`,
+			mockSetup:      func(*MockRestorationsService, *MockExecutionsService, *MockDatabasesService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Exactly one of database_id or connection_string is required",
+			},
+		},
+		{
+			name:           "Error - Data only and schema only",
+			body:           `{"execution_id":"123e4567-e89b-12d3-a456-426614174000","database_id":"123e4567-e89b-12d3-a456-426614174001","data_only":true,"schema_only":true}`,
+			mockSetup:      func(*MockRestorationsService, *MockExecutionsService, *MockDatabasesService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "data_only and schema_only cannot be both set",
+			},
+		},
+		{
+			name: "Error - Connection string test fails",
+			body: `{"execution_id":"123e4567-e89b-12d3-a456-426614174000","connection_string":"postgresql://localhost/db"}`,
+			mockSetup: func(_ *MockRestorationsService, es *MockExecutionsService, ds *MockDatabasesService) {
+				es.On("GetExecution", mock.Anything, executionID).Return(dbgen.ExecutionsServiceGetExecutionRow{}, nil)
+				ds.On("TestDatabase", mock.Anything, "postgresql://localhost/db").Return(postgres.PGVersion{}, assert.AnError)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": assert.AnError.Error(),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			e := echo.New()
+			mockRestorationsService := new(MockRestorationsService)
+			mockExecutionsService := new(MockExecutionsService)
+			mockDatabasesService := new(MockDatabasesService)
+			h := &mockHandlers{
+				servs: &mockService{
+					RestorationsService: mockRestorationsService,
+					ExecutionsService:   mockExecutionsService,
+					DatabasesService:    mockDatabasesService,
+				},
+			}
+			tc.mockSetup(mockRestorationsService, mockExecutionsService, mockDatabasesService)
+
+			// Create request
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/restorations", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Test handler
+			err := h.createRestorationHandler(c)
+
+			// Assertions
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBody, response)
+
+			// The restoration runs in a goroutine
+			if tc.expectRun {
+				select {
+				case <-started:
+				case <-time.After(time.Second):
+					t.Fatal("restoration was not started")
+				}
+				mockRestorationsService.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestCancelRestorationHandler(t *testing.T) {
+	restorationID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name           string
+		restorationID  string
+		mockSetup      func(*MockRestorationsService)
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:          "Success - Cancel restoration",
+			restorationID: restorationID.String(),
+			mockSetup: func(rs *MockRestorationsService) {
+				rs.On("CancelRestoration", mock.Anything, restorationID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"message": "Restoration cancelled",
+			},
+		},
+		{
+			name:           "Error - Invalid restoration ID",
+			restorationID:  "invalid",
+			mockSetup:      func(rs *MockRestorationsService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Invalid restoration ID",
+			},
+		},
+		{
+			name:          "Error - Restoration not running",
+			restorationID: restorationID.String(),
+			mockSetup: func(rs *MockRestorationsService) {
+				rs.On("CancelRestoration", mock.Anything, restorationID).Return(
+					errors.New("restoration is not running"),
+				)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "Failed to cancel restoration: restoration is not running",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			e := echo.New()
+			mockRestorationsService := new(MockRestorationsService)
+			h := &mockHandlers{
+				servs: &mockService{
+					RestorationsService: mockRestorationsService,
+				},
+			}
+			tc.mockSetup(mockRestorationsService)
+
+			// Create request
+			req := httptest.NewRequest(http.MethodPost, "/api/restorations/"+tc.restorationID+"/cancel", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.restorationID)
+
+			// Test handler
+			err := h.cancelRestorationHandler(c)
+
+			// Assertions
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBody, response)
+			mockRestorationsService.AssertExpectations(t)
+		})
+	}
+}
@@ -9,4 +9,6 @@ func MountRouter(parent *echo.Group, servs *service.Service) {
 	h := newHandlers(servs)
 
 	parent.GET("", h.listRestorationsHandler)
+	parent.POST("", h.createRestorationHandler)
+	parent.POST("/:id/cancel", h.cancelRestorationHandler)
 }
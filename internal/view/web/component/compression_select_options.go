@@ -0,0 +1,25 @@
+package component
+
+import (
+	"database/sql"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	nodx "github.com/nodxdev/nodxgo"
+)
+
+func CompressionSelectOptions(selectedCompression sql.NullString) nodx.Node {
+	return nodx.Map(
+		postgres.Compressions,
+		func(compression postgres.Compression) nodx.Node {
+			return nodx.Option(
+				nodx.Value(compression.Value.Key),
+				nodx.Text(compression.Value.Name),
+				nodx.If(
+					selectedCompression.Valid &&
+						selectedCompression.String == compression.Value.Key,
+					nodx.Selected(""),
+				),
+			)
+		},
+	)
+}
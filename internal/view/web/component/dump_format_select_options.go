@@ -0,0 +1,24 @@
+package component
+
+import (
+	"database/sql"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	nodx "github.com/nodxdev/nodxgo"
+)
+
+func DumpFormatSelectOptions(selectedFormat sql.NullString) nodx.Node {
+	return nodx.Map(
+		postgres.DumpFormats,
+		func(dumpFormat postgres.DumpFormat) nodx.Node {
+			return nodx.Option(
+				nodx.Value(dumpFormat.Value.Key),
+				nodx.Text(dumpFormat.Value.Name),
+				nodx.If(
+					selectedFormat.Valid && selectedFormat.String == dumpFormat.Value.Key,
+					nodx.Selected(""),
+				),
+			)
+		},
+	)
+}
@@ -9,11 +9,11 @@ func StatusBadge(status string) nodx.Node {
 	switch status {
 	case "running":
 		class = "badge-info"
-	case "success":
+	case "success", "ok":
 		class = "badge-success"
-	case "failed":
+	case "failed", "corrupted", "missing":
 		class = "badge-error"
-	case "deleted":
+	case "deleted", "cancelled":
 		class = "badge-warning"
 	default:
 		class = "badge-neutral"
@@ -1,10 +1,19 @@
 package backups
 
 import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/google/uuid"
 	nodx "github.com/nodxdev/nodxgo"
+	alpine "github.com/nodxdev/nodxgo-alpine"
 	lucide "github.com/nodxdev/nodxgo-lucide"
 )
 
@@ -29,6 +38,126 @@ func localBackupsHelp() []nodx.Node {
 	}
 }
 
+func backupKindHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Database backups use pg_dump to back up a single database. They don't
+				include cluster-wide objects like roles, grants on them and
+				tablespaces, so restoring them onto a fresh server can fail.
+			`),
+
+			component.PText(`
+				Globals backups use pg_dumpall --globals-only to back up the roles and
+				tablespaces of the whole server. Roles backups use --roles-only to back
+				up only the roles. Both can be applied before restoring a database
+				backup.
+			`),
+
+			component.PText(`
+				Physical backups use pg_basebackup to take a copy of the whole data
+				directory of the server. The database connection must use a role with
+				the REPLICATION attribute and the server must accept replication
+				connections. They are restored manually following the recovery
+				instructions included with each execution.
+			`),
+
+			component.PText(`
+				Only database backups use the options and filters below.
+			`),
+		),
+	}
+}
+
+func walArchivingHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				When enabled, PG Back Web keeps a pg_receivewal process streaming the
+				WAL of the server and uploads every completed WAL file to the
+				destination of the backup, under the wal directory.
+			`),
+
+			component.PText(`
+				Combined with the base backups, the archived WAL allows to recover the
+				server to any point in time from the restorations page. WAL files older
+				than the oldest base backup are deleted automatically.
+			`),
+
+			component.PText(`
+				A physical replication slot is created in the server so no WAL is lost
+				while PG Back Web is down. The slot is dropped when WAL archiving is
+				disabled, the backup task is deactivated or it is deleted. If the
+				server can't be reached at that moment, the slot must be dropped by
+				hand, otherwise the server keeps the WAL files forever.
+			`),
+		),
+	}
+}
+
+func compressionHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				The compression codec used for the backup files. Zstandard compresses
+				better and faster than gzip, LZ4 is the fastest but compresses less.
+				Custom and directory archives are already compressed by pg_dump, so
+				you can select none for them.
+			`),
+
+			component.PText(`
+				The level goes from 1 (fastest) to 9 for gzip, 12 for LZ4 and 19 for
+				Zstandard (smallest files). Leave it at 0 to use the default level of
+				the codec.
+			`),
+
+			component.PText(`
+				The file extension follows the format and the codec, for example
+				.sql.zst, .dump.gz or .tar.lz4. Changing the compression only affects
+				new executions, the old ones are restored with the codec they were
+				created with.
+			`),
+		),
+	}
+}
+
+func compressionFields(compression string, level int16) nodx.Node {
+	return nodx.Group(
+		component.SelectControl(component.SelectControlParams{
+			Name:               "opt_compression",
+			Label:              "Compression",
+			Required:           true,
+			HelpButtonChildren: compressionHelp(),
+			Children: []nodx.Node{
+				component.CompressionSelectOptions(
+					sql.NullString{Valid: true, String: compression},
+				),
+			},
+		}),
+
+		component.InputControl(component.InputControlParams{
+			Name:        "opt_compression_level",
+			Label:       "Compression level",
+			Placeholder: "0",
+			Required:    true,
+			Type:        component.InputTypeNumber,
+			Pattern:     "[0-9]+",
+			HelpText:    "0 uses the default level of the codec",
+			Children: []nodx.Node{
+				nodx.Min("0"),
+				nodx.Max("19"),
+				nodx.Value(fmt.Sprintf("%d", level)),
+			},
+		}),
+	)
+}
+
 func cronExpressionHelp() []nodx.Node {
 	return []nodx.Node{
 		component.PText(`
@@ -109,7 +238,7 @@ func destinationDirectoryHelp() []nodx.Node {
 					"font-mono":             true,
 				},
 				component.BText(
-					"/backups/<destination-directory>/<YYYY>/<MM>/<DD>/dump-<random-suffix>.zip",
+					"/backups/<destination-directory>/<YYYY>/<MM>/<DD>/dump-<random-suffix>.<extension>",
 				),
 			),
 		),
@@ -128,7 +257,7 @@ func destinationDirectoryHelp() []nodx.Node {
 					"font-mono":             true,
 				},
 				component.BText(
-					"s3://<bucket>/<destination-directory>/<YYYY>/<MM>/<DD>/dump-<random-suffix>.zip",
+					"s3://<bucket>/<destination-directory>/<YYYY>/<MM>/<DD>/dump-<random-suffix>.<extension>",
 				),
 			),
 		),
@@ -153,6 +282,45 @@ func retentionDaysHelp() []nodx.Node {
 	}
 }
 
+func timeoutHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				The timeout is the maximum number of minutes an execution can take,
+				including the hooks. When it is exceeded the backup process is killed,
+				the execution is marked as failed and the failure webhooks are sent.
+			`),
+
+			component.PText(`
+				If you set the timeout to 0, the default of the server is used, which
+				is set with the PBW_BACKUP_TIMEOUT environment variable and is 24 hours
+				unless it is changed.
+			`),
+		),
+	}
+}
+
+func parallelJobsHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Number of tables that pg_dump will dump simultaneously. The same number
+				of workers will be used by pg_restore when the backup is restored.
+			`),
+
+			component.PText(`
+				Parallel jobs are only supported by the directory format. Each job opens
+				a new connection to the database, so make sure the database allows
+				enough connections.
+			`),
+		),
+	}
+}
+
 func pgDumpOptionsHelp() []nodx.Node {
 	return []nodx.Node{
 		nodx.Div(
@@ -168,6 +336,13 @@ func pgDumpOptionsHelp() []nodx.Node {
 				PG Back Web does not pass any options so the backups are full backups.
 			`),
 
+			component.PText(`
+				The format option selects how the dump is stored. Plain SQL dumps are
+				restored using psql. Custom and directory archives are compressed by
+				pg_dump and restored using pg_restore, which handles the dependencies
+				between objects and allows selective restores.
+			`),
+
 			nodx.Div(
 				nodx.Class("flex justify-end"),
 				nodx.A(
@@ -181,3 +356,613 @@ func pgDumpOptionsHelp() []nodx.Node {
 		),
 	}
 }
+
+func dumpOptionsHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				--no-owner and --no-privileges leave out the ownership and the grants
+				of the objects, so the backup can be restored in a server that doesn't
+				have the same roles.
+			`),
+
+			component.PText(`
+				--lock-wait-timeout makes the backup fail instead of waiting forever
+				when a table is locked by another session, use a number with an
+				optional unit like 30s or 5min. --role runs the dump as another role
+				after connecting, for example a read-only role.
+			`),
+
+			component.PText(`
+				--serializable-deferrable waits until the snapshot is guaranteed to be
+				free of anomalies before dumping, which is useful for backups of
+				databases with serializable transactions.
+			`),
+
+			component.PText(`
+				--extension limits the dumped extensions to the matching patterns,
+				write one pattern per line. It requires PostgreSQL 14 or newer.
+			`),
+		),
+	}
+}
+
+// dumpOptionsForm contains the form fields rendered by dumpOptionsFields, it
+// is embedded in the forms to create and edit backups.
+type dumpOptionsForm struct {
+	OptNoOwner                string `form:"opt_no_owner" validate:"omitempty,oneof=true false"`
+	OptNoPrivileges           string `form:"opt_no_privileges" validate:"omitempty,oneof=true false"`
+	OptNoBlobs                string `form:"opt_no_blobs" validate:"omitempty,oneof=true false"`
+	OptLockWaitTimeout        string `form:"opt_lock_wait_timeout"`
+	OptRole                   string `form:"opt_role"`
+	OptQuoteAllIdentifiers    string `form:"opt_quote_all_identifiers" validate:"omitempty,oneof=true false"`
+	OptNoPublications         string `form:"opt_no_publications" validate:"omitempty,oneof=true false"`
+	OptNoSubscriptions        string `form:"opt_no_subscriptions" validate:"omitempty,oneof=true false"`
+	OptSerializableDeferrable string `form:"opt_serializable_deferrable" validate:"omitempty,oneof=true false"`
+	OptExtensions             string `form:"opt_extensions"`
+}
+
+// dumpOptionsJSON returns the JSON document of the dump options of the form.
+func (f dumpOptionsForm) dumpOptionsJSON() (string, error) {
+	data, err := json.Marshal(postgres.DumpOptions{
+		NoOwner:                f.OptNoOwner == "true",
+		NoPrivileges:           f.OptNoPrivileges == "true",
+		NoBlobs:                f.OptNoBlobs == "true",
+		LockWaitTimeout:        strings.TrimSpace(f.OptLockWaitTimeout),
+		Role:                   strings.TrimSpace(f.OptRole),
+		QuoteAllIdentifiers:    f.OptQuoteAllIdentifiers == "true",
+		NoPublications:         f.OptNoPublications == "true",
+		NoSubscriptions:        f.OptNoSubscriptions == "true",
+		SerializableDeferrable: f.OptSerializableDeferrable == "true",
+		Extensions:             strutil.SplitLines(f.OptExtensions),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error encoding dump options: %w", err)
+	}
+	return string(data), nil
+}
+
+func dumpOptionsFields(options postgres.DumpOptions) nodx.Node {
+	yesNo := func(name string, value bool) nodx.Node {
+		return component.SelectControl(component.SelectControlParams{
+			Name:     name,
+			Label:    "--" + strings.ReplaceAll(strings.TrimPrefix(name, "opt_"), "_", "-"),
+			Required: true,
+			Children: []nodx.Node{
+				nodx.Option(
+					nodx.Value("true"), nodx.Text("Yes"),
+					nodx.If(value, nodx.Selected("")),
+				),
+				nodx.Option(
+					nodx.Value("false"), nodx.Text("No"),
+					nodx.If(!value, nodx.Selected("")),
+				),
+			},
+		})
+	}
+
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			component.H2Text("Advanced options"),
+			component.HelpButtonModal(component.HelpButtonModalParams{
+				ModalTitle: "Advanced backup options",
+				Children:   dumpOptionsHelp(),
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 grid grid-cols-2 gap-2"),
+
+			yesNo("opt_no_owner", options.NoOwner),
+			yesNo("opt_no_privileges", options.NoPrivileges),
+			yesNo("opt_no_blobs", options.NoBlobs),
+			yesNo("opt_quote_all_identifiers", options.QuoteAllIdentifiers),
+			yesNo("opt_no_publications", options.NoPublications),
+			yesNo("opt_no_subscriptions", options.NoSubscriptions),
+			yesNo("opt_serializable_deferrable", options.SerializableDeferrable),
+
+			component.InputControl(component.InputControlParams{
+				Name:        "opt_lock_wait_timeout",
+				Label:       "--lock-wait-timeout",
+				Placeholder: "30s",
+				Type:        component.InputTypeText,
+				Pattern:     `^\d+(ms|s|min|h|d)?$`,
+				HelpText:    "Empty waits forever",
+				Children: []nodx.Node{
+					nodx.Value(options.LockWaitTimeout),
+				},
+			}),
+
+			component.InputControl(component.InputControlParams{
+				Name:        "opt_role",
+				Label:       "--role",
+				Placeholder: "backup_reader",
+				Type:        component.InputTypeText,
+				HelpText:    "Empty uses the role of the connection",
+				Children: []nodx.Node{
+					nodx.Value(options.Role),
+				},
+			}),
+		),
+
+		component.TextareaControl(component.TextareaControlParams{
+			Name:        "opt_extensions",
+			Label:       "--extension",
+			Placeholder: "postgis",
+			HelpText:    "One pattern per line, requires PostgreSQL 14 or newer",
+			Children: []nodx.Node{
+				nodx.Text(strings.Join(options.Extensions, "\n")),
+			},
+		}),
+	)
+}
+
+func filtersHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Filters limit which schemas and tables are included in the backup. Write
+				one pattern per line. Patterns follow the same rules as psql's \d
+				commands, so wildcards like public.audit_* are allowed.
+			`),
+
+			component.PText(`
+				--exclude-table-data keeps the definition of the matching tables but
+				skips their rows, which is useful for big audit or log tables.
+			`),
+
+			component.PText(`
+				If no filters are set, the whole database is included in the backup.
+			`),
+		),
+	}
+}
+
+type filtersFieldsParams struct {
+	Schemas          []string
+	ExcludeSchemas   []string
+	Tables           []string
+	ExcludeTables    []string
+	ExcludeTableData []string
+}
+
+func filtersFields(params filtersFieldsParams) nodx.Node {
+	textarea := func(name, label, placeholder string, values []string) nodx.Node {
+		return component.TextareaControl(component.TextareaControlParams{
+			Name:        name,
+			Label:       label,
+			Placeholder: placeholder,
+			HelpText:    "One pattern per line",
+			Children: []nodx.Node{
+				nodx.Text(strings.Join(values, "\n")),
+			},
+		})
+	}
+
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			component.H2Text("Filters"),
+			component.HelpButtonModal(component.HelpButtonModalParams{
+				ModalTitle: "Backup filters",
+				Children:   filtersHelp(),
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 grid grid-cols-2 gap-2"),
+			textarea("opt_schemas", "--schema", "public", params.Schemas),
+			textarea(
+				"opt_exclude_schemas", "--exclude-schema", "archive",
+				params.ExcludeSchemas,
+			),
+			textarea("opt_tables", "--table", "public.users", params.Tables),
+			textarea(
+				"opt_exclude_tables", "--exclude-table", "public.tmp_*",
+				params.ExcludeTables,
+			),
+			textarea(
+				"opt_exclude_table_data", "--exclude-table-data", "public.audit_logs",
+				params.ExcludeTableData,
+			),
+		),
+	)
+}
+
+func verificationHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Restore drills prove that the backups can actually be restored. On
+				their own schedule, the latest successful execution is restored into a
+				temporary database created in the verification server, the checks are
+				run against it and the database is dropped.
+			`),
+
+			component.PText(`
+				Use a server that is not in production, the drill uses its CPU, disk
+				and connections while it runs. Owners and privileges are not restored,
+				so the roles of the backup don't need to exist in it.
+			`),
+
+			component.PText(`
+				Each check is a SQL query that must return true, for example:
+				SELECT count(*) > 0 FROM public.users. The outcome is stored in the
+				execution and the verification webhooks are triggered.
+			`),
+		),
+	}
+}
+
+type verificationFieldsParams struct {
+	IsActive       bool
+	CronExpression string
+	DatabaseID     uuid.NullUUID
+	Checks         []string
+}
+
+func verificationFields(
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
+	params verificationFieldsParams,
+) nodx.Node {
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			component.H2Text("Restore drills"),
+			component.HelpButtonModal(component.HelpButtonModalParams{
+				ModalTitle: "Restore drills",
+				Children:   verificationHelp(),
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 grid grid-cols-2 gap-2"),
+
+			component.SelectControl(component.SelectControlParams{
+				Name:     "verify_is_active",
+				Label:    "Verify backups",
+				Required: true,
+				Children: []nodx.Node{
+					nodx.Option(
+						nodx.Value("true"), nodx.Text("Yes"),
+						nodx.If(params.IsActive, nodx.Selected("")),
+					),
+					nodx.Option(
+						nodx.Value("false"), nodx.Text("No"),
+						nodx.If(!params.IsActive, nodx.Selected("")),
+					),
+				},
+			}),
+
+			component.InputControl(component.InputControlParams{
+				Name:               "verify_cron_expression",
+				Label:              "Verification cron expression",
+				Placeholder:        "0 4 * * 0",
+				Type:               component.InputTypeText,
+				HelpText:           "Evaluated in the time zone of the backup",
+				Pattern:            `^\S+\s+\S+\s+\S+\s+\S+\s+\S+$`,
+				HelpButtonChildren: cronExpressionHelp(),
+				Children: []nodx.Node{
+					nodx.Value(params.CronExpression),
+				},
+			}),
+		),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:        "verify_database_id",
+			Label:       "Verification server",
+			Placeholder: "Select a database",
+			HelpText:    "The temporary database is created in the server of this database",
+			Children: []nodx.Node{
+				nodx.Map(
+					databases,
+					func(db dbgen.DatabasesServiceGetAllDatabasesRow) nodx.Node {
+						return nodx.Option(
+							nodx.Value(db.ID.String()),
+							nodx.Text(db.Name),
+							nodx.If(
+								params.DatabaseID.Valid && params.DatabaseID.UUID == db.ID,
+								nodx.Selected(""),
+							),
+						)
+					},
+				),
+			},
+		}),
+
+		component.TextareaControl(component.TextareaControlParams{
+			Name:        "verify_checks",
+			Label:       "Checks",
+			Placeholder: "SELECT count(*) > 0 FROM public.users",
+			HelpText:    "One query per line, each must return true",
+			Children: []nodx.Node{
+				nodx.Text(strings.Join(params.Checks, "\n")),
+			},
+		}),
+	)
+}
+
+func hooksHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Hooks run before and after each backup, for example to run a
+				CHECKPOINT, pause a job queue, refresh materialized views or notify
+				another system. The post-backup hook runs even if the backup failed,
+				so whatever the pre-backup hook paused is resumed.
+			`),
+
+			component.PText(`
+				The SQL runs with psql in a single transaction against the database of
+				the backup. The command runs with sh -c in the PG Back Web server, the
+				connection string is available in the PBW_CONNECTION_STRING environment
+				variable and the password in PGPASSWORD.
+			`),
+
+			component.PText(`
+				The SQL and the command are stopped if they don't finish within the
+				timeout. Their output is stored in the execution. When a hook fails,
+				the abort policy marks the execution as failed, a failed pre-backup
+				hook also skips the backup, and the continue policy ignores the error.
+			`),
+		),
+	}
+}
+
+type hooksFieldsParams struct {
+	PreHookSQL      string
+	PreHookCommand  string
+	PostHookSQL     string
+	PostHookCommand string
+	TimeoutSeconds  int32
+	AbortOnFailure  bool
+}
+
+func hooksFields(params hooksFieldsParams) nodx.Node {
+	textarea := func(name, label, placeholder, value string) nodx.Node {
+		return component.TextareaControl(component.TextareaControlParams{
+			Name:        name,
+			Label:       label,
+			Placeholder: placeholder,
+			Children: []nodx.Node{
+				nodx.Text(value),
+			},
+		})
+	}
+
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			component.H2Text("Hooks"),
+			component.HelpButtonModal(component.HelpButtonModalParams{
+				ModalTitle: "Backup hooks",
+				Children:   hooksHelp(),
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 grid grid-cols-2 gap-2"),
+			textarea("pre_hook_sql", "Pre-backup SQL", "CHECKPOINT;", params.PreHookSQL),
+			textarea(
+				"pre_hook_command", "Pre-backup command",
+				"curl -X POST https://example.com/queue/pause", params.PreHookCommand,
+			),
+			textarea(
+				"post_hook_sql", "Post-backup SQL",
+				"REFRESH MATERIALIZED VIEW public.stats;", params.PostHookSQL,
+			),
+			textarea(
+				"post_hook_command", "Post-backup command",
+				"curl -X POST https://example.com/queue/resume", params.PostHookCommand,
+			),
+
+			component.InputControl(component.InputControlParams{
+				Name:        "hook_timeout_seconds",
+				Label:       "Hook timeout (seconds)",
+				Placeholder: "300",
+				Required:    true,
+				Type:        component.InputTypeNumber,
+				Pattern:     "[0-9]+",
+				Children: []nodx.Node{
+					nodx.Min("1"),
+					nodx.Max("86400"),
+					nodx.Value(fmt.Sprintf("%d", params.TimeoutSeconds)),
+				},
+			}),
+
+			component.SelectControl(component.SelectControlParams{
+				Name:     "hook_failure_policy",
+				Label:    "When a hook fails",
+				Required: true,
+				Children: []nodx.Node{
+					nodx.Option(
+						nodx.Value("abort"), nodx.Text("Fail the execution"),
+						nodx.If(params.AbortOnFailure, nodx.Selected("")),
+					),
+					nodx.Option(
+						nodx.Value("continue"), nodx.Text("Continue"),
+						nodx.If(!params.AbortOnFailure, nodx.Selected("")),
+					),
+				},
+			}),
+		),
+	)
+}
+
+func retriesHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Failed executions are retried until the backup succeeds or the max
+				attempts are reached, so transient errors like a network issue don't
+				leave the backup failed until the next scheduled run. Set the max
+				attempts to 1 to disable the retries.
+			`),
+
+			component.PText(`
+				The first retry waits the initial delay and every next retry multiplies
+				the previous delay by the backoff factor, up to 24 hours. For example,
+				60 seconds with a factor of 2 waits 1, 2 and 4 minutes.
+			`),
+
+			component.PText(`
+				Every attempt is a new execution with the same run ID. The failure
+				webhooks only run when the last attempt fails, cancelled executions are
+				not retried.
+			`),
+		),
+	}
+}
+
+type retriesFieldsParams struct {
+	MaxAttempts         int32
+	InitialDelaySeconds int32
+	BackoffFactor       float64
+}
+
+func retriesFields(params retriesFieldsParams) nodx.Node {
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			component.H2Text("Retries"),
+			component.HelpButtonModal(component.HelpButtonModalParams{
+				ModalTitle: "Backup retries",
+				Children:   retriesHelp(),
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 grid grid-cols-3 gap-2"),
+
+			component.InputControl(component.InputControlParams{
+				Name:        "retry_max_attempts",
+				Label:       "Max attempts",
+				Placeholder: "1",
+				Required:    true,
+				Type:        component.InputTypeNumber,
+				Pattern:     "[0-9]+",
+				Children: []nodx.Node{
+					nodx.Min("1"),
+					nodx.Max("10"),
+					nodx.Value(fmt.Sprintf("%d", params.MaxAttempts)),
+				},
+			}),
+
+			component.InputControl(component.InputControlParams{
+				Name:        "retry_initial_delay_seconds",
+				Label:       "Initial delay (seconds)",
+				Placeholder: "60",
+				Required:    true,
+				Type:        component.InputTypeNumber,
+				Pattern:     "[0-9]+",
+				Children: []nodx.Node{
+					nodx.Min("1"),
+					nodx.Max("86400"),
+					nodx.Value(fmt.Sprintf("%d", params.InitialDelaySeconds)),
+				},
+			}),
+
+			component.InputControl(component.InputControlParams{
+				Name:        "retry_backoff_factor",
+				Label:       "Backoff factor",
+				Placeholder: "2",
+				Required:    true,
+				Type:        component.InputTypeNumber,
+				Children: []nodx.Node{
+					nodx.Min("1"),
+					nodx.Max("10"),
+					nodx.Step("0.1"),
+					nodx.Value(fmt.Sprintf("%g", params.BackoffFactor)),
+				},
+			}),
+		),
+	)
+}
+
+func encryptionHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Encrypted backups are encrypted with AES-256-GCM before they leave PG
+				Back Web, so the files stored in the destination can't be read without
+				the passphrase. The passphrase is stored encrypted with your
+				PBW_ENCRYPTION_KEY.
+			`),
+
+			component.PText(`
+				Restorations decrypt the files automatically, and the executions can be
+				downloaded decrypted or as they are stored. Changing the passphrase
+				only affects new executions, the old ones keep their own passphrase.
+			`),
+
+			component.PText(`
+				The archived WAL files of physical backups are encrypted too.
+				Point-in-time recovery bundles contain the base backup and the WAL
+				files already decrypted.
+			`),
+		),
+	}
+}
+
+// encryptionFields renders the fields to enable, change or disable the
+// encryption of a backup, isEncrypted is true if it is already encrypted.
+func encryptionFields(isEncrypted bool) nodx.Node {
+	return nodx.Div(
+		nodx.Class("grid grid-cols-2 gap-2"),
+		alpine.XData(`{ encryption: "keep" }`),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:               "encryption",
+			Label:              "Encryption",
+			Required:           true,
+			HelpButtonChildren: encryptionHelp(),
+			Children: []nodx.Node{
+				alpine.XModel("encryption"),
+				nodx.If(
+					isEncrypted,
+					nodx.Group(
+						nodx.Option(nodx.Value("keep"), nodx.Text("Keep passphrase")),
+						nodx.Option(nodx.Value("change"), nodx.Text("Change passphrase")),
+						nodx.Option(nodx.Value("disable"), nodx.Text("Disable encryption")),
+					),
+				),
+				nodx.If(
+					!isEncrypted,
+					nodx.Group(
+						nodx.Option(nodx.Value("keep"), nodx.Text("No")),
+						nodx.Option(nodx.Value("change"), nodx.Text("Yes")),
+					),
+				),
+			},
+		}),
+
+		nodx.Div(
+			alpine.XShow("encryption === 'change'"),
+			component.InputControl(component.InputControlParams{
+				Name:         "encryption_passphrase",
+				Label:        "Encryption passphrase",
+				Type:         component.InputTypePassword,
+				AutoComplete: "new-password",
+				HelpText:     "Keep a copy, it is needed to decrypt the files",
+			}),
+		),
+	)
+}
@@ -1,12 +1,15 @@
 package backups
 
 import (
+	"database/sql"
 	"net/http"
 	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/staticdata"
 	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
 	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
@@ -23,6 +26,7 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 
 	var formData struct {
 		DatabaseID     uuid.UUID `form:"database_id" validate:"required,uuid"`
+		Kind           string    `form:"kind" validate:"required,oneof=database globals roles physical"`
 		DestinationID  uuid.UUID `form:"destination_id" validate:"omitempty,uuid"`
 		IsLocal        string    `form:"is_local" validate:"required,oneof=true false"`
 		Name           string    `form:"name" validate:"required"`
@@ -37,6 +41,42 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 		OptIfExists    string    `form:"opt_if_exists" validate:"required,oneof=true false"`
 		OptCreate      string    `form:"opt_create" validate:"required,oneof=true false"`
 		OptNoComments  string    `form:"opt_no_comments" validate:"required,oneof=true false"`
+		OptFormat      string    `form:"opt_format" validate:"required,oneof=plain custom directory"`
+		OptJobs        int16     `form:"opt_jobs" validate:"required,min=1,max=32"`
+
+		TimeoutMinutes int32 `form:"timeout_minutes" validate:"min=0"`
+
+		OptWalArchiving string `form:"opt_wal_archiving" validate:"omitempty,oneof=true false"`
+
+		OptCompression      string `form:"opt_compression" validate:"required,oneof=none gzip zstd lz4"`
+		OptCompressionLevel int16  `form:"opt_compression_level" validate:"min=0,max=19"`
+
+		OptSchemas          string `form:"opt_schemas"`
+		OptExcludeSchemas   string `form:"opt_exclude_schemas"`
+		OptTables           string `form:"opt_tables"`
+		OptExcludeTables    string `form:"opt_exclude_tables"`
+		OptExcludeTableData string `form:"opt_exclude_table_data"`
+
+		VerifyIsActive       string    `form:"verify_is_active" validate:"omitempty,oneof=true false"`
+		VerifyCronExpression string    `form:"verify_cron_expression"`
+		VerifyDatabaseID     uuid.UUID `form:"verify_database_id" validate:"omitempty,uuid"`
+		VerifyChecks         string    `form:"verify_checks"`
+
+		Encryption           string `form:"encryption" validate:"required,oneof=keep change disable"`
+		EncryptionPassphrase string `form:"encryption_passphrase"`
+
+		PreHookSQL         string `form:"pre_hook_sql"`
+		PreHookCommand     string `form:"pre_hook_command"`
+		PostHookSQL        string `form:"post_hook_sql"`
+		PostHookCommand    string `form:"post_hook_command"`
+		HookTimeoutSeconds int32  `form:"hook_timeout_seconds" validate:"required,min=1,max=86400"`
+		HookFailurePolicy  string `form:"hook_failure_policy" validate:"required,oneof=abort continue"`
+
+		RetryMaxAttempts         int32   `form:"retry_max_attempts" validate:"required,min=1,max=10"`
+		RetryInitialDelaySeconds int32   `form:"retry_initial_delay_seconds" validate:"required,min=1,max=86400"`
+		RetryBackoffFactor       float64 `form:"retry_backoff_factor" validate:"required,min=1,max=10"`
+
+		dumpOptionsForm
 	}
 	if err := c.Bind(&formData); err != nil {
 		return respondhtmx.ToastError(c, err.Error())
@@ -45,9 +85,15 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
-	_, err := h.servs.BackupsService.CreateBackup(
+	dumpOptions, err := formData.dumpOptionsJSON()
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	_, err = h.servs.BackupsService.CreateBackup(
 		ctx, dbgen.BackupsServiceCreateBackupParams{
 			DatabaseID: formData.DatabaseID,
+			Kind:       formData.Kind,
 			DestinationID: uuid.NullUUID{
 				Valid: formData.IsLocal == "false", UUID: formData.DestinationID,
 			},
@@ -64,6 +110,47 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 			OptIfExists:    formData.OptIfExists == "true",
 			OptCreate:      formData.OptCreate == "true",
 			OptNoComments:  formData.OptNoComments == "true",
+			OptFormat:      formData.OptFormat,
+			OptJobs:        formData.OptJobs,
+
+			TimeoutMinutes: formData.TimeoutMinutes,
+
+			OptWalArchiving: formData.OptWalArchiving == "true",
+
+			OptCompression:      formData.OptCompression,
+			OptCompressionLevel: formData.OptCompressionLevel,
+
+			OptSchemas:          strutil.SplitLines(formData.OptSchemas),
+			OptExcludeSchemas:   strutil.SplitLines(formData.OptExcludeSchemas),
+			OptTables:           strutil.SplitLines(formData.OptTables),
+			OptExcludeTables:    strutil.SplitLines(formData.OptExcludeTables),
+			OptExcludeTableData: strutil.SplitLines(formData.OptExcludeTableData),
+
+			OptDumpOptions: dumpOptions,
+
+			PreHookSql:         formData.PreHookSQL,
+			PreHookCommand:     formData.PreHookCommand,
+			PostHookSql:        formData.PostHookSQL,
+			PostHookCommand:    formData.PostHookCommand,
+			HookTimeoutSeconds: formData.HookTimeoutSeconds,
+			HookFailurePolicy:  formData.HookFailurePolicy,
+
+			RetryMaxAttempts:         formData.RetryMaxAttempts,
+			RetryInitialDelaySeconds: formData.RetryInitialDelaySeconds,
+			RetryBackoffFactor:       formData.RetryBackoffFactor,
+
+			VerifyIsActive:       formData.VerifyIsActive == "true",
+			VerifyCronExpression: formData.VerifyCronExpression,
+			VerifyDatabaseID: uuid.NullUUID{
+				Valid: formData.VerifyDatabaseID != uuid.Nil,
+				UUID:  formData.VerifyDatabaseID,
+			},
+			VerifyChecks: strutil.SplitLines(formData.VerifyChecks),
+
+			EncryptionPassphrase: sql.NullString{
+				Valid:  formData.Encryption == "change",
+				String: formData.EncryptionPassphrase,
+			},
 		},
 	)
 	if err != nil {
@@ -111,6 +198,7 @@ func createBackupForm(
 
 		alpine.XData(`{
 			is_local: "false",
+			kind: "database",
 		}`),
 
 		component.InputControl(component.InputControlParams{
@@ -136,6 +224,29 @@ func createBackupForm(
 			},
 		}),
 
+		component.SelectControl(component.SelectControlParams{
+			Name:     "kind",
+			Label:    "Backup type",
+			Required: true,
+			Children: []nodx.Node{
+				alpine.XModel("kind"),
+				nodx.Option(
+					nodx.Value("database"), nodx.Text("Database (pg_dump)"),
+					nodx.Selected(""),
+				),
+				nodx.Option(
+					nodx.Value("globals"), nodx.Text("Globals (pg_dumpall --globals-only)"),
+				),
+				nodx.Option(
+					nodx.Value("roles"), nodx.Text("Roles (pg_dumpall --roles-only)"),
+				),
+				nodx.Option(
+					nodx.Value("physical"), nodx.Text("Physical (pg_basebackup)"),
+				),
+			},
+			HelpButtonChildren: backupKindHelp(),
+		}),
+
 		component.SelectControl(component.SelectControlParams{
 			Name:     "is_local",
 			Label:    "Local backup",
@@ -223,6 +334,21 @@ func createBackupForm(
 			},
 		}),
 
+		component.InputControl(component.InputControlParams{
+			Name:               "timeout_minutes",
+			Label:              "Timeout minutes",
+			Placeholder:        "0",
+			Required:           true,
+			Type:               component.InputTypeNumber,
+			Pattern:            "[0-9]+",
+			HelpText:           "0 uses the default of the server",
+			HelpButtonChildren: timeoutHelp(),
+			Children: []nodx.Node{
+				nodx.Min("0"),
+				nodx.Value("0"),
+			},
+		}),
+
 		component.SelectControl(component.SelectControlParams{
 			Name:     "is_active",
 			Label:    "Activate backup",
@@ -233,8 +359,31 @@ func createBackupForm(
 			},
 		}),
 
+		alpine.Template(
+			alpine.XIf("kind === 'physical'"),
+			component.SelectControl(component.SelectControlParams{
+				Name:               "opt_wal_archiving",
+				Label:              "WAL archiving",
+				Required:           true,
+				HelpText:           "Enables point-in-time recovery",
+				HelpButtonChildren: walArchivingHelp(),
+				Children: []nodx.Node{
+					yesNoOptions(),
+				},
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("grid grid-cols-2 gap-2"),
+			alpine.XShow("kind !== 'physical'"),
+			compressionFields(postgres.CompressionZstd.Value.Key, 0),
+		),
+
+		encryptionFields(false),
+
 		nodx.Div(
 			nodx.Class("pt-4"),
+			alpine.XShow("kind === 'database'"),
 			nodx.Div(
 				nodx.Class("flex justify-start items-center space-x-1"),
 				component.H2Text("Options"),
@@ -247,6 +396,33 @@ func createBackupForm(
 			nodx.Div(
 				nodx.Class("mt-2 grid grid-cols-2 gap-2"),
 
+				component.SelectControl(component.SelectControlParams{
+					Name:     "opt_format",
+					Label:    "--format",
+					Required: true,
+					Children: []nodx.Node{
+						component.DumpFormatSelectOptions(
+							sql.NullString{Valid: true, String: "plain"},
+						),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:               "opt_jobs",
+					Label:              "--jobs",
+					Placeholder:        "1",
+					Required:           true,
+					Type:               component.InputTypeNumber,
+					Pattern:            "[0-9]+",
+					HelpText:           "Only for the directory format",
+					HelpButtonChildren: parallelJobsHelp(),
+					Children: []nodx.Node{
+						nodx.Min("1"),
+						nodx.Max("32"),
+						nodx.Value("1"),
+					},
+				}),
+
 				component.SelectControl(component.SelectControlParams{
 					Name:     "opt_data_only",
 					Label:    "--data-only",
@@ -303,6 +479,32 @@ func createBackupForm(
 			),
 		),
 
+		nodx.Div(
+			alpine.XShow("kind === 'database'"),
+			dumpOptionsFields(postgres.DumpOptions{}),
+		),
+
+		nodx.Div(
+			alpine.XShow("kind === 'database'"),
+			filtersFields(filtersFieldsParams{}),
+		),
+
+		nodx.Div(
+			alpine.XShow("kind === 'database'"),
+			verificationFields(databases, verificationFieldsParams{}),
+		),
+
+		hooksFields(hooksFieldsParams{
+			TimeoutSeconds: 300,
+			AbortOnFailure: true,
+		}),
+
+		retriesFields(retriesFieldsParams{
+			MaxAttempts:         1,
+			InitialDelaySeconds: 60,
+			BackoffFactor:       2,
+		}),
+
 		nodx.Div(
 			nodx.Class("flex justify-end items-center space-x-2 pt-2"),
 			component.HxLoadingMd(),
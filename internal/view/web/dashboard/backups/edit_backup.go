@@ -5,7 +5,9 @@ import (
 	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/staticdata"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
 	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
@@ -37,6 +39,42 @@ func (h *handlers) editBackupHandler(c echo.Context) error {
 		OptIfExists    string `form:"opt_if_exists" validate:"required,oneof=true false"`
 		OptCreate      string `form:"opt_create" validate:"required,oneof=true false"`
 		OptNoComments  string `form:"opt_no_comments" validate:"required,oneof=true false"`
+		OptFormat      string `form:"opt_format" validate:"required,oneof=plain custom directory"`
+		OptJobs        int16  `form:"opt_jobs" validate:"required,min=1,max=32"`
+
+		TimeoutMinutes int32 `form:"timeout_minutes" validate:"min=0"`
+
+		OptWalArchiving string `form:"opt_wal_archiving" validate:"omitempty,oneof=true false"`
+
+		OptCompression      string `form:"opt_compression" validate:"omitempty,oneof=none gzip zstd lz4"`
+		OptCompressionLevel int16  `form:"opt_compression_level" validate:"min=0,max=19"`
+
+		OptSchemas          string `form:"opt_schemas"`
+		OptExcludeSchemas   string `form:"opt_exclude_schemas"`
+		OptTables           string `form:"opt_tables"`
+		OptExcludeTables    string `form:"opt_exclude_tables"`
+		OptExcludeTableData string `form:"opt_exclude_table_data"`
+
+		VerifyIsActive       string    `form:"verify_is_active" validate:"omitempty,oneof=true false"`
+		VerifyCronExpression string    `form:"verify_cron_expression"`
+		VerifyDatabaseID     uuid.UUID `form:"verify_database_id" validate:"omitempty,uuid"`
+		VerifyChecks         string    `form:"verify_checks"`
+
+		Encryption           string `form:"encryption" validate:"required,oneof=keep change disable"`
+		EncryptionPassphrase string `form:"encryption_passphrase"`
+
+		PreHookSQL         string `form:"pre_hook_sql"`
+		PreHookCommand     string `form:"pre_hook_command"`
+		PostHookSQL        string `form:"post_hook_sql"`
+		PostHookCommand    string `form:"post_hook_command"`
+		HookTimeoutSeconds int32  `form:"hook_timeout_seconds" validate:"required,min=1,max=86400"`
+		HookFailurePolicy  string `form:"hook_failure_policy" validate:"required,oneof=abort continue"`
+
+		RetryMaxAttempts         int32   `form:"retry_max_attempts" validate:"required,min=1,max=10"`
+		RetryInitialDelaySeconds int32   `form:"retry_initial_delay_seconds" validate:"required,min=1,max=86400"`
+		RetryBackoffFactor       float64 `form:"retry_backoff_factor" validate:"required,min=1,max=10"`
+
+		dumpOptionsForm
 	}
 	if err := c.Bind(&formData); err != nil {
 		return respondhtmx.ToastError(c, err.Error())
@@ -45,6 +83,11 @@ func (h *handlers) editBackupHandler(c echo.Context) error {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
+	dumpOptions, err := formData.dumpOptionsJSON()
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
 	_, err = h.servs.BackupsService.UpdateBackup(
 		ctx, dbgen.BackupsServiceUpdateBackupParams{
 			ID:             backupID,
@@ -60,6 +103,75 @@ func (h *handlers) editBackupHandler(c echo.Context) error {
 			OptIfExists:    sql.NullBool{Bool: formData.OptIfExists == "true", Valid: true},
 			OptCreate:      sql.NullBool{Bool: formData.OptCreate == "true", Valid: true},
 			OptNoComments:  sql.NullBool{Bool: formData.OptNoComments == "true", Valid: true},
+			OptFormat:      sql.NullString{String: formData.OptFormat, Valid: true},
+			OptJobs:        sql.NullInt16{Int16: formData.OptJobs, Valid: true},
+
+			TimeoutMinutes: sql.NullInt32{Int32: formData.TimeoutMinutes, Valid: true},
+
+			OptWalArchiving: sql.NullBool{
+				Valid: formData.OptWalArchiving != "",
+				Bool:  formData.OptWalArchiving == "true",
+			},
+			OptCompression: sql.NullString{
+				Valid:  formData.OptCompression != "",
+				String: formData.OptCompression,
+			},
+			OptCompressionLevel: sql.NullInt16{
+				Valid: formData.OptCompression != "",
+				Int16: formData.OptCompressionLevel,
+			},
+
+			OptSchemas:          strutil.SplitLines(formData.OptSchemas),
+			OptExcludeSchemas:   strutil.SplitLines(formData.OptExcludeSchemas),
+			OptTables:           strutil.SplitLines(formData.OptTables),
+			OptExcludeTables:    strutil.SplitLines(formData.OptExcludeTables),
+			OptExcludeTableData: strutil.SplitLines(formData.OptExcludeTableData),
+
+			OptDumpOptions: sql.NullString{String: dumpOptions, Valid: true},
+
+			PreHookSql:      sql.NullString{String: formData.PreHookSQL, Valid: true},
+			PreHookCommand:  sql.NullString{String: formData.PreHookCommand, Valid: true},
+			PostHookSql:     sql.NullString{String: formData.PostHookSQL, Valid: true},
+			PostHookCommand: sql.NullString{String: formData.PostHookCommand, Valid: true},
+			HookTimeoutSeconds: sql.NullInt32{
+				Int32: formData.HookTimeoutSeconds, Valid: true,
+			},
+			HookFailurePolicy: sql.NullString{
+				String: formData.HookFailurePolicy, Valid: true,
+			},
+
+			RetryMaxAttempts: sql.NullInt32{
+				Int32: formData.RetryMaxAttempts, Valid: true,
+			},
+			RetryInitialDelaySeconds: sql.NullInt32{
+				Int32: formData.RetryInitialDelaySeconds, Valid: true,
+			},
+			RetryBackoffFactor: sql.NullFloat64{
+				Float64: formData.RetryBackoffFactor, Valid: true,
+			},
+
+			VerifyIsActive: sql.NullBool{
+				Valid: formData.VerifyIsActive != "",
+				Bool:  formData.VerifyIsActive == "true",
+			},
+			VerifyCronExpression: sql.NullString{
+				Valid:  formData.VerifyIsActive != "",
+				String: formData.VerifyCronExpression,
+			},
+			VerifyDatabaseID: uuid.NullUUID{
+				Valid: formData.VerifyDatabaseID != uuid.Nil,
+				UUID:  formData.VerifyDatabaseID,
+			},
+			VerifyChecks: strutil.SplitLines(formData.VerifyChecks),
+
+			EncryptionPassphrase: sql.NullString{
+				Valid:  formData.Encryption == "change",
+				String: formData.EncryptionPassphrase,
+			},
+			RemoveEncryption: sql.NullBool{
+				Valid: formData.Encryption == "disable",
+				Bool:  true,
+			},
 		},
 	)
 	if err != nil {
@@ -69,7 +181,10 @@ func (h *handlers) editBackupHandler(c echo.Context) error {
 	return respondhtmx.AlertWithRefresh(c, "Backup task updated")
 }
 
-func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
+func editBackupButton(
+	backup dbgen.BackupsServicePaginateBackupsRow,
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
+) nodx.Node {
 	yesNoOptions := func(value bool) nodx.Node {
 		return nodx.Group(
 			nodx.Option(
@@ -85,6 +200,9 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 		)
 	}
 
+	// The stored options are validated when they are saved
+	dumpOptions, _ := postgres.Client{}.ParseDumpOptions(backup.OptDumpOptions)
+
 	mo := component.Modal(component.ModalParams{
 		Size:  component.SizeLg,
 		Title: "Edit backup task",
@@ -171,6 +289,21 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 					},
 				}),
 
+				component.InputControl(component.InputControlParams{
+					Name:               "timeout_minutes",
+					Label:              "Timeout minutes",
+					Placeholder:        "0",
+					Required:           true,
+					Type:               component.InputTypeNumber,
+					Pattern:            "[0-9]+",
+					HelpText:           "0 uses the default of the server",
+					HelpButtonChildren: timeoutHelp(),
+					Children: []nodx.Node{
+						nodx.Min("0"),
+						nodx.Value(fmt.Sprintf("%d", backup.TimeoutMinutes)),
+					},
+				}),
+
 				component.SelectControl(component.SelectControlParams{
 					Name:     "is_active",
 					Label:    "Activate backup",
@@ -180,6 +313,30 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 					},
 				}),
 
+				nodx.If(
+					backup.Kind == "physical",
+					component.SelectControl(component.SelectControlParams{
+						Name:               "opt_wal_archiving",
+						Label:              "WAL archiving",
+						Required:           true,
+						HelpText:           "Enables point-in-time recovery",
+						HelpButtonChildren: walArchivingHelp(),
+						Children: []nodx.Node{
+							yesNoOptions(backup.OptWalArchiving),
+						},
+					}),
+				),
+
+				nodx.If(
+					backup.Kind != "physical",
+					nodx.Div(
+						nodx.Class("grid grid-cols-2 gap-2"),
+						compressionFields(backup.OptCompression, backup.OptCompressionLevel),
+					),
+				),
+
+				encryptionFields(backup.EncryptionPassphrase != nil),
+
 				nodx.Div(
 					nodx.Class("pt-4"),
 					nodx.Div(
@@ -193,6 +350,33 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 
 					nodx.Div(
 						nodx.Class("mt-2 grid grid-cols-2 gap-2"),
+						component.SelectControl(component.SelectControlParams{
+							Name:     "opt_format",
+							Label:    "--format",
+							Required: true,
+							Children: []nodx.Node{
+								component.DumpFormatSelectOptions(
+									sql.NullString{Valid: true, String: backup.OptFormat},
+								),
+							},
+						}),
+
+						component.InputControl(component.InputControlParams{
+							Name:               "opt_jobs",
+							Label:              "--jobs",
+							Placeholder:        "1",
+							Required:           true,
+							Type:               component.InputTypeNumber,
+							Pattern:            "[0-9]+",
+							HelpText:           "Only for the directory format",
+							HelpButtonChildren: parallelJobsHelp(),
+							Children: []nodx.Node{
+								nodx.Min("1"),
+								nodx.Max("32"),
+								nodx.Value(fmt.Sprintf("%d", backup.OptJobs)),
+							},
+						}),
+
 						component.SelectControl(component.SelectControlParams{
 							Name:     "opt_data_only",
 							Label:    "--data-only",
@@ -249,6 +433,41 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 					),
 				),
 
+				dumpOptionsFields(dumpOptions),
+
+				filtersFields(filtersFieldsParams{
+					Schemas:          backup.OptSchemas,
+					ExcludeSchemas:   backup.OptExcludeSchemas,
+					Tables:           backup.OptTables,
+					ExcludeTables:    backup.OptExcludeTables,
+					ExcludeTableData: backup.OptExcludeTableData,
+				}),
+
+				nodx.If(
+					backup.Kind == "database",
+					verificationFields(databases, verificationFieldsParams{
+						IsActive:       backup.VerifyIsActive,
+						CronExpression: backup.VerifyCronExpression,
+						DatabaseID:     backup.VerifyDatabaseID,
+						Checks:         backup.VerifyChecks,
+					}),
+				),
+
+				hooksFields(hooksFieldsParams{
+					PreHookSQL:      backup.PreHookSql,
+					PreHookCommand:  backup.PreHookCommand,
+					PostHookSQL:     backup.PostHookSql,
+					PostHookCommand: backup.PostHookCommand,
+					TimeoutSeconds:  backup.HookTimeoutSeconds,
+					AbortOnFailure:  backup.HookFailurePolicy == "abort",
+				}),
+
+				retriesFields(retriesFieldsParams{
+					MaxAttempts:         backup.RetryMaxAttempts,
+					InitialDelaySeconds: backup.RetryInitialDelaySeconds,
+					BackoffFactor:       backup.RetryBackoffFactor,
+				}),
+
 				nodx.Div(
 					nodx.Class("flex justify-end items-center space-x-2 pt-2"),
 					component.HxLoadingMd(),
@@ -41,14 +41,20 @@ func (h *handlers) listBackupsHandler(c echo.Context) error {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
+	databases, err := h.servs.DatabasesService.GetAllDatabases(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
 	return echoutil.RenderNodx(
-		c, http.StatusOK, listBackups(pagination, backups),
+		c, http.StatusOK, listBackups(pagination, backups, databases),
 	)
 }
 
 func listBackups(
 	pagination paginateutil.PaginateResponse,
 	backups []dbgen.BackupsServicePaginateBackupsRow,
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
 ) nodx.Node {
 	if len(backups) < 1 {
 		return component.EmptyResultsTr(component.EmptyResultsParams{
@@ -78,7 +84,7 @@ func listBackups(
 					component.SpanText("Show executions"),
 				),
 				manualRunbutton(backup.ID),
-				editBackupButton(backup),
+				editBackupButton(backup, databases),
 				duplicateBackupButton(backup.ID),
 				deleteBackupButton(backup.ID),
 			)),
@@ -88,9 +88,35 @@ func listDatabases(
 						database.TestOk, database.TestError, database.LastTestAt,
 					),
 					component.SpanText(database.Name),
+					nodx.If(
+						database.MissingSince.Valid,
+						nodx.Span(
+							nodx.Class("tooltip tooltip-right text-warning"),
+							nodx.Data("tip", fmt.Sprintf(
+								"Not found in its server since %s",
+								database.MissingSince.Time.Local().Format(
+									timeutil.LayoutYYYYMMDDHHMMSSPretty,
+								),
+							)),
+							lucide.TriangleAlert(nodx.Class("size-4")),
+						),
+					),
+				),
+			),
+			nodx.Td(
+				nodx.Div(
+					nodx.Class("flex items-center space-x-1"),
+					component.SpanText("PostgreSQL "+database.PgVersion),
+					nodx.If(
+						database.PgVersionWarning.Valid,
+						nodx.Span(
+							nodx.Class("tooltip tooltip-right text-warning"),
+							nodx.Data("tip", database.PgVersionWarning.String),
+							lucide.TriangleAlert(nodx.Class("size-4")),
+						),
+					),
 				),
 			),
-			nodx.Td(component.SpanText("PostgreSQL "+database.PgVersion)),
 			nodx.Td(
 				nodx.Class("space-x-1"),
 				component.CopyButtonSm(database.DecryptedConnectionString),
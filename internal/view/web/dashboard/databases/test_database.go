@@ -1,6 +1,10 @@
 package databases
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
 	"github.com/google/uuid"
@@ -18,14 +22,73 @@ func (h *handlers) testDatabaseHandler(c echo.Context) error {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
-	err := h.servs.DatabasesService.TestDatabase(
-		ctx, formData.Version, formData.ConnectionString,
+	connString, err := h.connString(ctx, formData)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	connParams, err := h.connectionParams(ctx, formData)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	version, err := h.servs.DatabasesService.TestDatabaseWithParams(
+		ctx, connString, connParams,
 	)
 	if err != nil {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
-	return respondhtmx.ToastSuccess(c, "Connection successful")
+	return respondhtmx.ToastSuccess(c, fmt.Sprintf(
+		"Connection successful, PostgreSQL %s detected", version.Value.Version,
+	))
+}
+
+// connectionParams returns the SSH tunnel and TLS settings of the form. When
+// a database is being edited, the stored private key and TLS files are used
+// if they are kept.
+func (h *handlers) connectionParams(
+	ctx context.Context, formData createDatabaseDTO,
+) (postgres.ConnectionParams, error) {
+	params := postgres.ConnectionParams{
+		SSHTunnel: postgres.SSHTunnelParams{
+			Host:       formData.SSHHost,
+			User:       formData.SSHUser,
+			PrivateKey: formData.SSHPrivateKey,
+			HostKey:    formData.SSHHostKey,
+		},
+	}
+	if formData.TLSFiles == "change" {
+		params.TLS = postgres.TLSParams{
+			RootCert:   formData.TLSRootCert,
+			ClientCert: formData.TLSClientCert,
+			ClientKey:  formData.TLSClientKey,
+		}
+	}
+	if formData.DatabaseID == "" {
+		return params, nil
+	}
+
+	databaseID, err := uuid.Parse(formData.DatabaseID)
+	if err != nil {
+		return params, err
+	}
+	db, err := h.servs.DatabasesService.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return params, err
+	}
+
+	if params.SSHTunnel.IsEnabled() && params.SSHTunnel.PrivateKey == "" {
+		params.SSHTunnel.PrivateKey = db.DecryptedSshPrivateKey
+	}
+	if formData.TLSFiles == "keep" {
+		params.TLS = postgres.TLSParams{
+			RootCert:   db.DecryptedTlsRootCert,
+			ClientCert: db.DecryptedTlsClientCert,
+			ClientKey:  db.DecryptedTlsClientKey,
+		}
+	}
+	return params, nil
 }
 
 func (h *handlers) testExistingDatabaseHandler(c echo.Context) error {
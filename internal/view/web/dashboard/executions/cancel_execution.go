@@ -0,0 +1,38 @@
+package executions
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) cancelExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	err = h.servs.ExecutionsService.CancelExecution(ctx, executionID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Refresh(c)
+}
+
+func cancelExecutionButton(executionID uuid.UUID) nodx.Node {
+	return nodx.Button(
+		htmx.HxPost("/dashboard/executions/"+executionID.String()+"/cancel"),
+		htmx.HxDisabledELT("this"),
+		htmx.HxConfirm("Are you sure you want to cancel this execution? The partial backup will be discarded and the post-backup hooks will still run."),
+		nodx.Class("btn btn-warning btn-outline"),
+		component.SpanText("Cancel execution"),
+		lucide.CircleStop(),
+	)
+}
@@ -0,0 +1,133 @@
+package executions
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) executionContentsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	objects, err := h.servs.ExecutionsService.InspectExecution(ctx, executionID)
+	if err != nil {
+		return echoutil.RenderNodx(c, http.StatusOK, nodx.Div(
+			nodx.Role("alert"),
+			nodx.Class("alert alert-error"),
+			lucide.CircleX(),
+			component.SpanText("Error reading the backup contents: "+err.Error()),
+		))
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, executionContents(objects))
+}
+
+func executionContents(objects []postgres.DumpObject) nodx.Node {
+	if len(objects) == 0 {
+		return component.PText("The backup contains no objects.")
+	}
+
+	counts := map[string]int{}
+	for _, object := range objects {
+		counts[object.Type]++
+	}
+
+	summary := []string{}
+	for _, t := range []string{
+		"SCHEMA", "TABLE", "VIEW", "FUNCTION", "EXTENSION", "ROLE", "TABLESPACE",
+	} {
+		if counts[t] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[t], t))
+		}
+	}
+
+	return nodx.Div(
+		nodx.Class("space-y-2"),
+		nodx.If(
+			len(summary) > 0,
+			nodx.Div(
+				nodx.Class("flex flex-wrap gap-1"),
+				nodx.Map(summary, func(s string) nodx.Node {
+					return nodx.SpanEl(nodx.Class("badge badge-neutral"), nodx.Text(s))
+				}),
+			),
+		),
+		nodx.Div(
+			nodx.Class("max-h-96 overflow-auto"),
+			nodx.Table(
+				nodx.Class("table table-xs [&_th]:text-nowrap"),
+				nodx.Thead(
+					nodx.Tr(
+						nodx.Th(component.SpanText("Type")),
+						nodx.Th(component.SpanText("Schema")),
+						nodx.Th(component.SpanText("Name")),
+						nodx.Th(component.SpanText("Owner")),
+						nodx.Th(component.SpanText("Data size")),
+					),
+				),
+				nodx.Tbody(
+					nodx.Map(objects, func(object postgres.DumpObject) nodx.Node {
+						return nodx.Tr(
+							nodx.Td(nodx.Class("text-nowrap"), component.SpanText(object.Type)),
+							nodx.Td(component.SpanText(object.Schema)),
+							nodx.Td(nodx.Class("break-all"), component.SpanText(object.Name)),
+							nodx.Td(component.SpanText(object.Owner)),
+							nodx.Td(
+								nodx.Class("text-nowrap"),
+								nodx.If(
+									object.DataSize > 0,
+									component.SpanText(strutil.FormatFileSize(object.DataSize)),
+								),
+							),
+						)
+					}),
+				),
+			),
+		),
+		component.PText(`
+			Data sizes are approximate, they are measured as the uncompressed SQL
+			that loads the data of each table.
+		`),
+	)
+}
+
+// executionContentsSection renders a button that loads the objects contained
+// in the execution file, it is not loaded automatically because the whole
+// file is read.
+func executionContentsSection(executionID uuid.UUID) nodx.Node {
+	id := "execution-contents-" + executionID.String()
+
+	return nodx.Div(
+		nodx.Class("mt-2 mb-4 space-y-2"),
+		component.H3Text("Contents"),
+		nodx.Div(
+			nodx.Id(id),
+			nodx.Class("flex items-center space-x-2"),
+			nodx.Button(
+				nodx.Class("btn btn-sm btn-neutral"),
+				nodx.Type("button"),
+				htmx.HxGet("/dashboard/executions/"+executionID.String()+"/contents"),
+				htmx.HxTarget("#"+id),
+				htmx.HxIndicator("#"+id+"-loading"),
+				htmx.HxDisabledELT("this"),
+				component.SpanText("Show contents"),
+				lucide.List(),
+			),
+			component.HxLoadingSm(id+"-loading"),
+		),
+	)
+}
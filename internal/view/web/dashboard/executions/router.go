@@ -22,7 +22,10 @@ func MountRouter(
 	parent.GET("", h.indexPageHandler)
 	parent.GET("/list", h.listExecutionsHandler)
 	parent.GET("/:executionID/download", h.downloadExecutionHandler)
+	parent.GET("/:executionID/contents", h.executionContentsHandler)
 	parent.DELETE("/:executionID", h.deleteExecutionHandler)
+	parent.POST("/:executionID/cancel", h.cancelExecutionHandler)
 	parent.GET("/:executionID/restore-form", h.restoreExecutionFormHandler)
+	parent.GET("/:executionID/restore-objects", h.restoreExecutionObjectsHandler)
 	parent.POST("/:executionID/restore", h.restoreExecutionHandler)
 }
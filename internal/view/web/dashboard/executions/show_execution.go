@@ -1,10 +1,14 @@
 package executions
 
 import (
+	"fmt"
 	"net/http"
 	"path/filepath"
+	"strings"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
 	"github.com/google/uuid"
@@ -21,6 +25,10 @@ func (h *handlers) downloadExecutionHandler(c echo.Context) error {
 		return c.String(http.StatusBadRequest, err.Error())
 	}
 
+	if c.QueryParam("decrypted") == "true" {
+		return h.downloadDecryptedExecution(c, executionID)
+	}
+
 	isLocal, link, err := h.servs.ExecutionsService.GetExecutionDownloadLinkOrPath(
 		ctx, executionID,
 	)
@@ -35,6 +43,36 @@ func (h *handlers) downloadExecutionHandler(c echo.Context) error {
 	return c.Redirect(http.StatusFound, link)
 }
 
+// downloadDecryptedExecution streams the file of an encrypted execution
+// decrypting it on the fly, so the passphrase is not needed to use it.
+func (h *handlers) downloadDecryptedExecution(
+	c echo.Context, executionID uuid.UUID,
+) error {
+	ctx := c.Request().Context()
+
+	execution, err := h.servs.ExecutionsService.GetExecution(ctx, executionID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	file, err := h.servs.ExecutionsService.OpenDecryptedExecutionFile(
+		ctx, executionID,
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	defer file.Close()
+
+	fileName := strings.TrimSuffix(
+		filepath.Base(execution.Path.String), executions.EncryptedExtension,
+	)
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=%q", fileName),
+	)
+	return c.Stream(http.StatusOK, echo.MIMEOctetStream, file)
+}
+
 func showExecutionButton(
 	execution dbgen.ExecutionsServicePaginateExecutionsRow,
 ) nodx.Node {
@@ -54,6 +92,18 @@ func showExecutionButton(
 						nodx.Th(component.SpanText("Status")),
 						nodx.Td(component.StatusBadge(execution.Status)),
 					),
+					nodx.Tr(
+						nodx.Th(component.SpanText("Kind")),
+						nodx.Td(component.SpanText(execution.Kind)),
+					),
+					nodx.Tr(
+						nodx.Th(component.SpanText("Run ID")),
+						nodx.Td(component.SpanText(execution.RunID.String())),
+					),
+					nodx.Tr(
+						nodx.Th(component.SpanText("Attempt")),
+						nodx.Td(component.SpanText(fmt.Sprintf("%d", execution.Attempt))),
+					),
 					nodx.Tr(
 						nodx.Th(component.SpanText("Database")),
 						nodx.Td(component.SpanText(execution.DatabaseName)),
@@ -114,18 +164,133 @@ func showExecutionButton(
 							nodx.Td(component.PrettyFileSize(execution.FileSize)),
 						),
 					),
+					nodx.If(
+						execution.EncryptionPassphrase != nil,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Encrypted")),
+							nodx.Td(component.SpanText("Yes (AES-256-GCM)")),
+						),
+					),
+					nodx.If(
+						execution.Checksum.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("SHA-256")),
+							nodx.Td(
+								nodx.Class("break-all font-mono text-xs"),
+								component.SpanText(execution.Checksum.String),
+							),
+						),
+					),
+					nodx.If(
+						execution.IntegrityStatus.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Integrity")),
+							nodx.Td(
+								nodx.Class("space-x-1"),
+								component.StatusBadge(execution.IntegrityStatus.String),
+								component.SpanText(
+									"checked at "+execution.IntegrityCheckedAt.Time.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+								),
+							),
+						),
+					),
+					nodx.If(
+						execution.VerificationStatus.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Verification")),
+							nodx.Td(component.StatusBadge(execution.VerificationStatus.String)),
+						),
+					),
+					nodx.If(
+						execution.VerificationMessage.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Verification message")),
+							nodx.Td(
+								nodx.Class("break-all"),
+								component.SpanText(execution.VerificationMessage.String),
+							),
+						),
+					),
+					nodx.If(
+						execution.VerifiedAt.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Verified at")),
+							nodx.Td(component.SpanText(
+								execution.VerifiedAt.Time.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+							)),
+						),
+					),
+					nodx.If(
+						execution.Jobs.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Parallel jobs")),
+							nodx.Td(component.SpanText(
+								fmt.Sprintf("%d", execution.Jobs.Int16),
+							)),
+						),
+					),
+				),
+				nodx.If(
+					execution.HooksOutput.Valid,
+					nodx.Div(
+						nodx.Class("mt-2 space-y-2"),
+						component.H3Text("Hooks output"),
+						nodx.Pre(
+							nodx.Class("p-2 text-xs bg-base-200 rounded overflow-auto max-h-64"),
+							nodx.Text(execution.HooksOutput.String),
+						),
+					),
+				),
+				nodx.If(
+					execution.Status == "success" && execution.Kind == "physical",
+					recoveryInstructions(execution.DatabasePgVersion),
+				),
+				nodx.If(
+					execution.Status == "success" && execution.Path.Valid &&
+						execution.Kind != "physical",
+					executionContentsSection(execution.ID),
+				),
+				nodx.If(
+					execution.Status == "running",
+					nodx.Div(
+						nodx.Class("flex justify-end items-center space-x-2"),
+						cancelExecutionButton(execution.ID),
+					),
 				),
 				nodx.If(
 					execution.Status == "success",
 					nodx.Div(
 						nodx.Class("flex justify-end items-center space-x-2"),
 						deleteExecutionButton(execution.ID),
-						nodx.A(
-							nodx.Href("/dashboard/executions/"+execution.ID.String()+"/download"),
-							nodx.Target("_blank"),
-							nodx.Class("btn btn-primary"),
-							component.SpanText("Download"),
-							lucide.Download(),
+						nodx.If(
+							execution.EncryptionPassphrase != nil,
+							nodx.A(
+								nodx.Href("/dashboard/executions/"+execution.ID.String()+"/download"),
+								nodx.Target("_blank"),
+								nodx.Class("btn btn-neutral"),
+								component.SpanText("Download encrypted"),
+								lucide.Lock(),
+							),
+						),
+						nodx.If(
+							execution.EncryptionPassphrase != nil,
+							nodx.A(
+								nodx.Href("/dashboard/executions/"+execution.ID.String()+"/download?decrypted=true"),
+								nodx.Target("_blank"),
+								nodx.Class("btn btn-primary"),
+								component.SpanText("Download decrypted"),
+								lucide.Download(),
+							),
+						),
+						nodx.If(
+							execution.EncryptionPassphrase == nil,
+							nodx.A(
+								nodx.Href("/dashboard/executions/"+execution.ID.String()+"/download"),
+								nodx.Target("_blank"),
+								nodx.Class("btn btn-primary"),
+								component.SpanText("Download"),
+								lucide.Download(),
+							),
 						),
 					),
 				),
@@ -142,3 +307,15 @@ func showExecutionButton(
 		),
 	)
 }
+
+// recoveryInstructions renders the steps to restore a physical base backup.
+func recoveryInstructions(pgVersion string) nodx.Node {
+	return nodx.Div(
+		nodx.Class("mt-2 mb-4"),
+		component.H3Text("Recovery instructions"),
+		nodx.Pre(
+			nodx.Class("mt-2 p-2 text-xs bg-base-200 rounded overflow-x-auto"),
+			nodx.Text(postgres.BaseBackupRecoveryInstructions(pgVersion)),
+		),
+	)
+}
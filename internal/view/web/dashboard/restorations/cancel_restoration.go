@@ -0,0 +1,38 @@
+package restorations
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) cancelRestorationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	restorationID, err := uuid.Parse(c.Param("restorationID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	err = h.servs.RestorationsService.CancelRestoration(ctx, restorationID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Refresh(c)
+}
+
+func cancelRestorationButton(restorationID uuid.UUID) nodx.Node {
+	return nodx.Button(
+		htmx.HxPost("/dashboard/restorations/"+restorationID.String()+"/cancel"),
+		htmx.HxDisabledELT("this"),
+		htmx.HxConfirm("Are you sure you want to cancel this restoration? The objects already restored are not rolled back."),
+		nodx.Class("btn btn-warning btn-outline"),
+		component.SpanText("Cancel restoration"),
+		lucide.CircleStop(),
+	)
+}
@@ -0,0 +1,186 @@
+package restorations
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) pitrBundleHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData struct {
+		BackupID   uuid.UUID `query:"backup_id" validate:"required,uuid"`
+		TargetTime string    `query:"target_time" validate:"required"`
+	}
+	if err := c.Bind(&queryData); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	if err := validate.Struct(&queryData); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	targetTime, err := time.ParseInLocation(
+		timeutil.LayoutInputDateTimeLocal, queryData.TargetTime, time.Local,
+	)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	w := &attachmentWriter{
+		c: c,
+		filename: fmt.Sprintf(
+			"pitr-%s.zip", targetTime.Format(timeutil.LayoutYYYYMMDDHHMMSS),
+		),
+	}
+	err = h.servs.WalSegmentsService.WritePITRBundle(
+		ctx, w, queryData.BackupID, targetTime,
+	)
+	if err != nil && !w.started {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if err != nil {
+		logger.Error("error writing PITR bundle", logger.KV{
+			"backup_id": queryData.BackupID.String(),
+			"error":     err,
+		})
+	}
+
+	return nil
+}
+
+// attachmentWriter sends the attachment headers before the first write, so
+// errors found before writing anything can still be returned as text.
+type attachmentWriter struct {
+	c        echo.Context
+	filename string
+	started  bool
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		res := w.c.Response()
+		res.Header().Set(echo.HeaderContentType, "application/zip")
+		res.Header().Set(
+			echo.HeaderContentDisposition,
+			fmt.Sprintf("attachment; filename=%q", w.filename),
+		)
+		res.WriteHeader(http.StatusOK)
+		w.started = true
+	}
+
+	return w.c.Response().Write(p)
+}
+
+func (h *handlers) pitrFormHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backups, err := h.servs.WalSegmentsService.GetPITRBackups(ctx)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, pitrForm(backups))
+}
+
+func pitrForm(backups []dbgen.WalSegmentsServiceGetPITRBackupsRow) nodx.Node {
+	if len(backups) < 1 {
+		return component.PText(`
+			There are no physical backups with WAL archiving enabled. Create one in
+			the backup tasks page to be able to recover to a point in time.
+		`)
+	}
+
+	serverTZ := time.Now().Location().String()
+
+	return nodx.FormEl(
+		nodx.Method("GET"),
+		nodx.Action("/dashboard/restorations/pitr-bundle"),
+		nodx.Target("_blank"),
+		nodx.Class("space-y-2 text-base"),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:        "backup_id",
+			Label:       "Backup",
+			Required:    true,
+			Placeholder: "Select a backup",
+			Children: []nodx.Node{
+				nodx.Map(
+					backups,
+					func(back dbgen.WalSegmentsServiceGetPITRBackupsRow) nodx.Node {
+						return nodx.Option(
+							nodx.Value(back.ID.String()),
+							nodx.Textf("%s (%s)", back.Name, back.DatabaseName),
+						)
+					},
+				),
+			},
+		}),
+
+		component.InputControl(component.InputControlParams{
+			Name:     "target_time",
+			Label:    "Target time",
+			Required: true,
+			Type:     component.InputTypeDatetimeLocal,
+			HelpText: fmt.Sprintf("In the server time zone (%s)", serverTZ),
+		}),
+
+		component.PText(`
+			The recovery bundle contains the latest base backup finished before the
+			target time, the archived WAL needed to replay it until the target time
+			and the instructions to restore it. It can be big, the download starts
+			once everything has been checked.
+		`),
+
+		nodx.Div(
+			nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+			nodx.Button(
+				nodx.Class("btn btn-primary"),
+				nodx.Type("submit"),
+				component.SpanText("Download recovery bundle"),
+				lucide.Download(),
+			),
+		),
+	)
+}
+
+func pitrButton() nodx.Node {
+	mo := component.Modal(component.ModalParams{
+		Size:  component.SizeMd,
+		Title: "Point-in-time recovery",
+		Content: []nodx.Node{
+			nodx.Div(
+				htmx.HxGet("/dashboard/restorations/pitr-form"),
+				htmx.HxSwap("outerHTML"),
+				htmx.HxTrigger("intersect once"),
+				nodx.Class("p-10 flex justify-center"),
+				component.HxLoadingMd(),
+			),
+		},
+	})
+
+	button := nodx.Button(
+		mo.OpenerAttr,
+		nodx.Class("btn btn-primary"),
+		component.SpanText("Point-in-time recovery"),
+		lucide.History(),
+	)
+
+	return nodx.Div(
+		nodx.Class("inline-block"),
+		mo.HTML,
+		button,
+	)
+}
@@ -21,4 +21,7 @@ func MountRouter(
 
 	parent.GET("", h.indexPageHandler)
 	parent.GET("/list", h.listRestorationsHandler)
+	parent.GET("/pitr-form", h.pitrFormHandler)
+	parent.GET("/pitr-bundle", h.pitrBundleHandler)
+	parent.POST("/:restorationID/cancel", h.cancelRestorationHandler)
 }
@@ -76,6 +76,13 @@ func showRestorationButton(
 					),
 				),
 			),
+			nodx.If(
+				restoration.Status == "running",
+				nodx.Div(
+					nodx.Class("flex justify-end items-center space-x-2 mt-4"),
+					cancelRestorationButton(restoration.ID),
+				),
+			),
 		},
 	})
 
@@ -10,6 +10,7 @@ import (
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/executions"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/profile"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/restorations"
+	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/servers"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/summary"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/webhooks"
 	"github.com/labstack/echo/v4"
@@ -22,6 +23,7 @@ func MountRouter(
 
 	summary.MountRouter(parent.Group(""), mids, servs)
 	databases.MountRouter(parent.Group("/databases"), mids, servs)
+	servers.MountRouter(parent.Group("/servers"), mids, servs)
 	destinations.MountRouter(parent.Group("/destinations"), mids, servs)
 	backups.MountRouter(parent.Group("/backups"), mids, servs)
 	executions.MountRouter(parent.Group("/executions"), mids, servs)
@@ -0,0 +1,148 @@
+package servers
+
+import (
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	nodx "github.com/nodxdev/nodxgo"
+)
+
+func serversHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				A server lists the databases of a PostgreSQL cluster using the
+				connection of one of its databases, usually the postgres maintenance
+				database. Every database that matches the patterns is added to the
+				databases, using the same credentials, SSH tunnel and TLS files, and
+				is backed up with a copy of the template backup task.
+			`),
+
+			component.PText(`
+				Servers are synced every 10 minutes, so databases created later are
+				backed up automatically. Databases that disappear from the server are
+				flagged as missing, their backups and executions are kept.
+			`),
+
+			component.PText(`
+				Changes to the template are applied to the backup tasks of the
+				server on the next sync, overwriting their settings. The name and
+				the destination directory of each task are the ones of the template
+				followed by the database name.
+			`),
+		),
+	}
+}
+
+func patternsHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Patterns use the shell glob syntax, where * matches any sequence of
+				characters, ? matches a single character and [0-9] matches a range,
+				for example app_* or tenant_[0-9]*.
+			`),
+
+			component.PText(`
+				A database is backed up if it matches any of the include patterns,
+				or there are none, and doesn't match any of the exclude patterns.
+				Template databases and databases that don't accept connections are
+				always skipped.
+			`),
+		),
+	}
+}
+
+type serverFieldsParams struct {
+	TemplateBackupID string
+	IncludePatterns  []string
+	ExcludePatterns  []string
+	IsActive         bool
+}
+
+// serverFields returns the fields shared by the create and edit forms.
+func serverFields(
+	templates []dbgen.Backup, params serverFieldsParams,
+) nodx.Node {
+	return nodx.Group(
+		component.SelectControl(component.SelectControlParams{
+			Name:        "template_backup_id",
+			Label:       "Template backup task",
+			Required:    true,
+			Placeholder: "Select a backup task",
+			HelpText:    "Its settings are copied to the backup task of every database",
+			Children: []nodx.Node{
+				nodx.Map(
+					templates,
+					func(backup dbgen.Backup) nodx.Node {
+						return nodx.Option(
+							nodx.Value(backup.ID.String()),
+							nodx.Text(backup.Name),
+							nodx.If(
+								backup.ID.String() == params.TemplateBackupID,
+								nodx.Selected(""),
+							),
+						)
+					},
+				),
+			},
+			HelpButtonChildren: serversHelp(),
+		}),
+
+		component.TextareaControl(component.TextareaControlParams{
+			Name:               "include_patterns",
+			Label:              "Include databases",
+			Placeholder:        "app_*",
+			HelpText:           "One pattern per line, empty to include all databases",
+			HelpButtonChildren: patternsHelp(),
+			Children: []nodx.Node{
+				nodx.Text(strings.Join(params.IncludePatterns, "\n")),
+			},
+		}),
+
+		component.TextareaControl(component.TextareaControlParams{
+			Name:               "exclude_patterns",
+			Label:              "Exclude databases",
+			Placeholder:        "postgres",
+			HelpText:           "One pattern per line",
+			HelpButtonChildren: patternsHelp(),
+			Children: []nodx.Node{
+				nodx.Text(strings.Join(params.ExcludePatterns, "\n")),
+			},
+		}),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:     "is_active",
+			Label:    "Activate sync",
+			Required: true,
+			HelpText: "Inactive servers don't discover new databases",
+			Children: []nodx.Node{
+				nodx.Option(
+					nodx.Value("true"), nodx.Text("Yes"),
+					nodx.If(params.IsActive, nodx.Selected("")),
+				),
+				nodx.Option(
+					nodx.Value("false"), nodx.Text("No"),
+					nodx.If(!params.IsActive, nodx.Selected("")),
+				),
+			},
+		}),
+	)
+}
+
+// templateBackups returns the backup tasks that can be used as template of a
+// server.
+func templateBackups(backups []dbgen.Backup) []dbgen.Backup {
+	templates := []dbgen.Backup{}
+	for _, backup := range backups {
+		if backup.Kind == "database" && !backup.ServerID.Valid {
+			templates = append(templates, backup)
+		}
+	}
+	return templates
+}
@@ -0,0 +1,149 @@
+package servers
+
+import (
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) createServerHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var formData struct {
+		Name             string    `form:"name" validate:"required"`
+		DatabaseID       uuid.UUID `form:"database_id" validate:"required,uuid"`
+		TemplateBackupID uuid.UUID `form:"template_backup_id" validate:"required,uuid"`
+		IncludePatterns  string    `form:"include_patterns"`
+		ExcludePatterns  string    `form:"exclude_patterns"`
+		IsActive         string    `form:"is_active" validate:"required,oneof=true false"`
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	_, err := h.servs.ServersService.CreateServer(
+		ctx, dbgen.ServersServiceCreateServerParams{
+			Name:             formData.Name,
+			DatabaseID:       formData.DatabaseID,
+			TemplateBackupID: formData.TemplateBackupID,
+			IncludePatterns:  strutil.SplitLines(formData.IncludePatterns),
+			ExcludePatterns:  strutil.SplitLines(formData.ExcludePatterns),
+			IsActive:         formData.IsActive == "true",
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Redirect(c, "/dashboard/servers")
+}
+
+func (h *handlers) createServerFormHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	databases, err := h.servs.DatabasesService.GetAllDatabases(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	backups, err := h.servs.BackupsService.GetAllBackups(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(
+		c, http.StatusOK, createServerForm(databases, templateBackups(backups)),
+	)
+}
+
+func createServerForm(
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
+	templates []dbgen.Backup,
+) nodx.Node {
+	return nodx.FormEl(
+		htmx.HxPost("/dashboard/servers"),
+		htmx.HxDisabledELT("find button"),
+		nodx.Class("space-y-2 text-base"),
+
+		component.InputControl(component.InputControlParams{
+			Name:        "name",
+			Label:       "Name",
+			Placeholder: "My server",
+			Required:    true,
+			Type:        component.InputTypeText,
+			HelpText:    "Used as prefix of the names of the discovered databases",
+		}),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:        "database_id",
+			Label:       "Connection database",
+			Required:    true,
+			Placeholder: "Select a database",
+			HelpText:    "Its connection is used to list and back up the databases of the server",
+			Children: []nodx.Node{
+				nodx.Map(
+					databases,
+					func(db dbgen.DatabasesServiceGetAllDatabasesRow) nodx.Node {
+						return nodx.Option(nodx.Value(db.ID.String()), nodx.Text(db.Name))
+					},
+				),
+			},
+			HelpButtonChildren: serversHelp(),
+		}),
+
+		serverFields(templates, serverFieldsParams{IsActive: true}),
+
+		nodx.Div(
+			nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+			component.HxLoadingMd(),
+			nodx.Button(
+				nodx.Class("btn btn-primary"),
+				nodx.Type("submit"),
+				component.SpanText("Save"),
+				lucide.Save(),
+			),
+		),
+	)
+}
+
+func createServerButton() nodx.Node {
+	mo := component.Modal(component.ModalParams{
+		Size:  component.SizeMd,
+		Title: "Create server",
+		Content: []nodx.Node{
+			nodx.Div(
+				htmx.HxGet("/dashboard/servers/create-form"),
+				htmx.HxSwap("outerHTML"),
+				htmx.HxTrigger("intersect once"),
+				nodx.Class("p-10 flex justify-center"),
+				component.HxLoadingMd(),
+			),
+		},
+	})
+
+	button := nodx.Button(
+		mo.OpenerAttr,
+		nodx.Class("btn btn-primary"),
+		component.SpanText("Create server"),
+		lucide.Plus(),
+	)
+
+	return nodx.Div(
+		nodx.Class("inline-block"),
+		mo.HTML,
+		button,
+	)
+}
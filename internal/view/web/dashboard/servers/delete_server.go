@@ -0,0 +1,38 @@
+package servers
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) deleteServerHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	serverID, err := uuid.Parse(c.Param("serverID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	if err = h.servs.ServersService.DeleteServer(ctx, serverID); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Refresh(c)
+}
+
+func deleteServerButton(serverID uuid.UUID) nodx.Node {
+	return component.OptionsDropdownButton(
+		htmx.HxDelete("/dashboard/servers/"+serverID.String()),
+		htmx.HxConfirm(
+			"Are you sure you want to delete this server? Its databases and "+
+				"backup tasks will be kept.",
+		),
+		lucide.Trash(),
+		component.SpanText("Delete server"),
+	)
+}
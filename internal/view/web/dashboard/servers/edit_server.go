@@ -0,0 +1,114 @@
+package servers
+
+import (
+	"database/sql"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) editServerHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	serverID, err := uuid.Parse(c.Param("serverID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	var formData struct {
+		Name             string    `form:"name" validate:"required"`
+		TemplateBackupID uuid.UUID `form:"template_backup_id" validate:"required,uuid"`
+		IncludePatterns  string    `form:"include_patterns"`
+		ExcludePatterns  string    `form:"exclude_patterns"`
+		IsActive         string    `form:"is_active" validate:"required,oneof=true false"`
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	_, err = h.servs.ServersService.UpdateServer(
+		ctx, dbgen.ServersServiceUpdateServerParams{
+			ID:   serverID,
+			Name: sql.NullString{String: formData.Name, Valid: true},
+			TemplateBackupID: uuid.NullUUID{
+				UUID: formData.TemplateBackupID, Valid: true,
+			},
+			IncludePatterns: strutil.SplitLines(formData.IncludePatterns),
+			ExcludePatterns: strutil.SplitLines(formData.ExcludePatterns),
+			IsActive: sql.NullBool{
+				Bool: formData.IsActive == "true", Valid: true,
+			},
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.AlertWithRefresh(c, "Server updated")
+}
+
+func editServerButton(
+	server dbgen.ServersServicePaginateServersRow, templates []dbgen.Backup,
+) nodx.Node {
+	mo := component.Modal(component.ModalParams{
+		Size:  component.SizeMd,
+		Title: "Edit server",
+		Content: []nodx.Node{
+			nodx.FormEl(
+				htmx.HxPost("/dashboard/servers/"+server.ID.String()+"/edit"),
+				htmx.HxDisabledELT("find button"),
+				nodx.Class("space-y-2 text-base"),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "name",
+					Label:       "Name",
+					Placeholder: "My server",
+					Required:    true,
+					Type:        component.InputTypeText,
+					HelpText:    "Used as prefix of the names of the discovered databases",
+					Children: []nodx.Node{
+						nodx.Value(server.Name),
+					},
+				}),
+
+				serverFields(templates, serverFieldsParams{
+					TemplateBackupID: server.TemplateBackupID.String(),
+					IncludePatterns:  server.IncludePatterns,
+					ExcludePatterns:  server.ExcludePatterns,
+					IsActive:         server.IsActive,
+				}),
+
+				nodx.Div(
+					nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+					component.HxLoadingMd(),
+					nodx.Button(
+						nodx.Class("btn btn-primary"),
+						nodx.Type("submit"),
+						component.SpanText("Save"),
+						lucide.Save(),
+					),
+				),
+			),
+		},
+	})
+
+	return nodx.Div(
+		mo.HTML,
+		component.OptionsDropdownButton(
+			mo.OpenerAttr,
+			lucide.Pencil(),
+			component.SpanText("Edit server"),
+		),
+	)
+}
@@ -0,0 +1,143 @@
+package servers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/servers"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) listServersHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var formData struct {
+		Page int `query:"page" validate:"required,min=1"`
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	pagination, servers, err := h.servs.ServersService.PaginateServers(
+		ctx, servers.PaginateServersParams{
+			Page:  formData.Page,
+			Limit: 20,
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	backups, err := h.servs.BackupsService.GetAllBackups(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(
+		c, http.StatusOK,
+		listServers(pagination, servers, templateBackups(backups)),
+	)
+}
+
+func listServers(
+	pagination paginateutil.PaginateResponse,
+	servers []dbgen.ServersServicePaginateServersRow,
+	templates []dbgen.Backup,
+) nodx.Node {
+	if len(servers) < 1 {
+		return component.EmptyResultsTr(component.EmptyResultsParams{
+			Title:    "No servers found",
+			Subtitle: "Wait for the first server to appear here",
+		})
+	}
+
+	trs := []nodx.Node{}
+	for _, server := range servers {
+		lastSync := "Never"
+		if server.LastSyncAt.Valid {
+			lastSync = server.LastSyncAt.Time.Local().Format(
+				timeutil.LayoutYYYYMMDDHHMMSSPretty,
+			)
+		}
+
+		trs = append(trs, nodx.Tr(
+			nodx.Td(component.OptionsDropdown(
+				nodx.Div(
+					nodx.Class("flex flex-col space-y-1"),
+					editServerButton(server, templates),
+					syncServerButton(server.ID),
+					deleteServerButton(server.ID),
+				),
+			)),
+			nodx.Td(
+				nodx.Div(
+					nodx.Class("flex items-center space-x-2"),
+					component.HealthStatusPing(
+						sql.NullBool{
+							Bool: !server.LastSyncError.Valid, Valid: server.LastSyncAt.Valid,
+						},
+						server.LastSyncError,
+						server.LastSyncAt,
+					),
+					component.SpanText(server.Name),
+					nodx.If(
+						!server.IsActive,
+						nodx.Span(
+							nodx.Class("badge badge-neutral"),
+							nodx.Text("Inactive"),
+						),
+					),
+				),
+			),
+			nodx.Td(component.SpanText(server.DatabaseName)),
+			nodx.Td(component.SpanText(server.TemplateBackupName)),
+			nodx.Td(
+				nodx.Div(
+					nodx.Class("flex items-center space-x-1"),
+					component.SpanText(fmt.Sprintf("%d", server.DatabasesCount)),
+					nodx.If(
+						server.MissingDatabasesCount > 0,
+						nodx.Span(
+							nodx.Class("tooltip tooltip-right text-warning"),
+							nodx.Data("tip", fmt.Sprintf(
+								"%d no longer found in the server",
+								server.MissingDatabasesCount,
+							)),
+							lucide.TriangleAlert(nodx.Class("size-4")),
+						),
+					),
+				),
+			),
+			nodx.Td(component.SpanText(lastSync)),
+			nodx.Td(component.SpanText(
+				server.CreatedAt.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+			)),
+		))
+	}
+
+	if pagination.HasNextPage {
+		trs = append(trs, nodx.Tr(
+			htmx.HxGet(fmt.Sprintf(
+				"/dashboard/servers/list?page=%d", pagination.NextPage,
+			)),
+			htmx.HxTrigger("intersect once"),
+			htmx.HxSwap("afterend"),
+		))
+	}
+
+	return component.RenderableGroup(trs)
+}
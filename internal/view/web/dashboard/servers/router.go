@@ -0,0 +1,29 @@
+package servers
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/view/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+type handlers struct {
+	servs *service.Service
+}
+
+func newHandlers(servs *service.Service) *handlers {
+	return &handlers{servs: servs}
+}
+
+func MountRouter(
+	parent *echo.Group, mids *middleware.Middleware, servs *service.Service,
+) {
+	h := newHandlers(servs)
+
+	parent.GET("", h.indexPageHandler)
+	parent.GET("/list", h.listServersHandler)
+	parent.GET("/create-form", h.createServerFormHandler)
+	parent.POST("", h.createServerHandler)
+	parent.DELETE("/:serverID", h.deleteServerHandler)
+	parent.POST("/:serverID/edit", h.editServerHandler)
+	parent.POST("/:serverID/sync", h.syncServerHandler)
+}
@@ -0,0 +1,35 @@
+package servers
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) syncServerHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	serverID, err := uuid.Parse(c.Param("serverID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	if err := h.servs.ServersService.SyncServer(ctx, serverID); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.AlertWithRefresh(c, "Server synced")
+}
+
+func syncServerButton(serverID uuid.UUID) nodx.Node {
+	return component.OptionsDropdownButton(
+		htmx.HxPost("/dashboard/servers/"+serverID.String()+"/sync"),
+		htmx.HxDisabledELT("this"),
+		lucide.RefreshCw(),
+		component.SpanText("Sync now"),
+	)
+}
@@ -126,6 +126,8 @@ func createAndUpdateWebhookForm(
 		webhooks.EventTypeDestinationUnhealthy.Value.Key: destinationSelect,
 		webhooks.EventTypeExecutionSuccess.Value.Key:     backupSelect,
 		webhooks.EventTypeExecutionFailed.Value.Key:      backupSelect,
+		webhooks.EventTypeVerificationSuccess.Value.Key:  backupSelect,
+		webhooks.EventTypeVerificationFailed.Value.Key:   backupSelect,
 	}
 
 	targetIdsSelect := []nodx.Node{}
@@ -56,6 +56,13 @@ func dashboardAside() nodx.Node {
 				false,
 			),
 
+			dashboardAsideItem(
+				lucide.Server,
+				"Servers",
+				"/dashboard/servers",
+				false,
+			),
+
 			dashboardAsideItem(
 				lucide.HardDrive,
 				"Destinations",